@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/epiphany-platform/cli/pkg/bundle"
+	"github.com/epiphany-platform/cli/pkg/registry"
+	"github.com/epiphany-platform/cli/pkg/repository"
+	"github.com/epiphany-platform/cli/pkg/runtime"
+	"github.com/epiphany-platform/cli/pkg/util"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportVersion            string
+	exportOutput             string
+	exportInsecureSkipVerify bool
+)
+
+// componentsExportCmd represents the export command
+var componentsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports component with its image to bundle file",
+	Long: `Exports component with its image to bundle file which can be imported with "e components import" on
+machine without access to repository and registry.
+
+Usage: e components export <name>[@version] [-o bundle.tar]
+
+Version is selected in the same way as in "e components install". Image is pulled if it's not present locally.
+Default output file is <name>-<version>.tar. Here --output (-o) flag selects output file instead of output format.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("components export called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		name, version, err := splitComponentReference(args[0], exportVersion)
+		if err != nil {
+			errIncorrectComponentReference(err)
+		}
+		skipRepositoryVerification(exportInsecureSkipVerify)
+		tc, err := repository.GetRepository().GetComponentByName(name)
+		if err != nil {
+			errGetComponentByName(err)
+		}
+		c, err := tc.JustVersion(version)
+		if err != nil {
+			errGetComponentWithVersion(err)
+		}
+		verifyComponent(c)
+
+		rt, err := runtime.New(util.UsedRuntime)
+		if err != nil {
+			errGetRuntime(err)
+		}
+		image := c.Versions[0].Image
+		if _, err := rt.Inspect(image); err != nil {
+			debug("image %s not found locally: %v", image, err)
+			auth, err := registry.Auth(image)
+			if err != nil {
+				errPullImage(err)
+			}
+			_, err = rt.Pull(image, auth, os.Stdout)
+			if err != nil {
+				errPullImage(err)
+			}
+		}
+
+		output := exportOutput
+		if output == "" {
+			output = fmt.Sprintf("%s-%s.tar", c.Name, c.Versions[0].Version)
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			errExportComponent(err)
+		}
+		err = bundle.Export(rt, c, f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(output)
+			errExportComponent(err)
+		}
+		fmt.Printf("Exported component %s %s to %s\n", c.Name, c.Versions[0].Version, output)
+	},
+}
+
+func init() {
+	componentsCmd.AddCommand(componentsExportCmd)
+
+	componentsExportCmd.Flags().StringVar(&exportVersion, "version", "", "version or semver range of component to export (default is version marked latest)")
+	componentsExportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "bundle file to write (default is <name>-<version>.tar)")
+	componentsExportCmd.Flags().BoolVar(&exportInsecureSkipVerify, "insecure-skip-verify", false, "do not verify signature of repository component comes from")
+}
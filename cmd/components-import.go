@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/epiphany-platform/cli/pkg/bundle"
+	"github.com/epiphany-platform/cli/pkg/runtime"
+	"github.com/epiphany-platform/cli/pkg/util"
+
+	"github.com/spf13/cobra"
+)
+
+// componentsImportCmd represents the import command
+var componentsImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Imports component with its image from bundle file",
+	Long: `Imports component with its image from bundle file created with "e components export".
+
+Usage: e components import <bundle.tar>
+
+Image is loaded to container runtime and component definition is stored in configuration directory, so component
+can be installed with "e components install" without access to repository and registry.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("components import called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		rt, err := runtime.New(util.UsedRuntime)
+		if err != nil {
+			errGetRuntime(err)
+		}
+		f, err := os.Open(args[0])
+		if err != nil {
+			errImportComponent(err)
+		}
+		defer f.Close()
+		c, err := bundle.Import(rt, f, os.Stdout)
+		if err != nil {
+			errImportComponent(err)
+		}
+		fmt.Printf("Imported component %s %s\n", c.Name, c.Versions[0].Version)
+	},
+}
+
+func init() {
+	componentsCmd.AddCommand(componentsImportCmd)
+}
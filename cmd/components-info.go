@@ -12,7 +12,13 @@ import (
 var componentsInfoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Displays information about component",
-	Long:  `TODO`,
+	Long: `Displays information about latest version of component available in repository.
+
+Usage: e components info <name>
+
+Name can be qualified with repository name (like internal/c1) to select component from specific repository.
+
+Output format can be selected with global --output flag (text, json, yaml or template=<go template>).`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		debug("components info called")
 	},
@@ -28,7 +34,7 @@ var componentsInfoCmd = &cobra.Command{
 		if err != nil {
 			errGetComponentWithLatestVersion(err)
 		}
-		fmt.Print(c.String())
+		printOutput(c, c.String())
 	},
 }
 
@@ -3,19 +3,34 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"strings"
 
-	"github.com/epiphany-platform/cli/pkg/configuration"
 	"github.com/epiphany-platform/cli/pkg/environment"
 	"github.com/epiphany-platform/cli/pkg/repository"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	installVersion            string
+	installInsecureSkipVerify bool
+)
+
 // componentsInstallCmd represents the install command
 var componentsInstallCmd = &cobra.Command{
 	Use:   "install",
 	Short: "Installs component into currently used environment",
-	Long:  `TODO`,
+	Long: `Installs component into currently used environment.
+
+Usage: e components install <name>[@version]
+
+By default version marked as latest is installed. Version can be provided either after @ sign or with --version
+flag. It can be exact version (like 0.1.0) or semver range (like ~0.1, ^1.2 or >=1.0.0) in which case the highest
+matching version is installed. Name can be qualified with repository name (like internal/c1) to select component
+from specific repository.
+
+If there are trusted keys in config file, component is installed only if its repository is signed with one of them,
+unless --insecure-skip-verify flag is provided.`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		debug("components install called")
 	},
@@ -23,43 +38,25 @@ var componentsInstallCmd = &cobra.Command{
 		if len(args) != 1 {
 			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
 		}
-		config, err := configuration.GetConfig()
+		name, version, err := splitComponentReference(args[0], installVersion)
 		if err != nil {
-			errGetConfig(err)
-		}
-		e, err := environment.Get(config.CurrentEnvironment)
-		if err != nil {
-			errGetEnvironments(err)
+			errIncorrectComponentReference(err)
 		}
+		e := getLockedEnvironment(cmd, args)
+		defer unlockEnvironment()
 
-		tc, err := repository.GetRepository().GetComponentByName(args[0])
+		skipRepositoryVerification(installInsecureSkipVerify)
+		tc, err := repository.GetRepository().GetComponentByName(name)
 		if err != nil {
 			errGetComponentByName(err)
 		}
-		c, err := tc.JustLatestVersion()
+		c, err := tc.JustVersion(version)
 		if err != nil {
-			errGetComponentWithLatestVersion(err)
+			errGetComponentWithVersion(err)
 		}
+		verifyComponent(c)
 
-		newComponent := environment.InstalledComponentVersion{
-			EnvironmentRef: e.Uuid,
-			Name:           c.Name,
-			Type:           c.Type,
-			Version:        c.Versions[0].Version,
-			Image:          c.Versions[0].Image,
-			WorkDirectory:  c.Versions[0].WorkDirectory,
-			Mounts:         c.Versions[0].Mounts,
-		}
-		for _, rc := range c.Versions[0].Commands {
-			nic := environment.InstalledComponentCommand{
-				Name:        rc.Name,
-				Description: rc.Description,
-				Command:     rc.Command,
-				Envs:        rc.Envs,
-				Args:        rc.Args,
-			}
-			newComponent.Commands = append(newComponent.Commands, nic)
-		}
+		newComponent := newInstalledComponentVersion(e, c)
 		err = e.Install(newComponent)
 		if err != nil {
 			errInstallComponent(err)
@@ -71,13 +68,70 @@ var componentsInstallCmd = &cobra.Command{
 func init() {
 	componentsCmd.AddCommand(componentsInstallCmd)
 
-	// Here you will define your flags and configuration settings.
+	componentsInstallCmd.Flags().StringVar(&installVersion, "version", "", "version or semver range of component to install (default is version marked latest)")
+	componentsInstallCmd.Flags().BoolVar(&installInsecureSkipVerify, "insecure-skip-verify", false, "do not verify signature of repository component comes from")
+}
+
+//splitComponentReference splits reference in form of name@version into name and version. Version provided in flag
+//is used when reference contains no version.
+func splitComponentReference(reference string, flagVersion string) (string, string, error) {
+	name, version := reference, ""
+	if i := strings.LastIndex(reference, "@"); i >= 0 {
+		name, version = reference[:i], reference[i+1:]
+		if version == "" {
+			return "", "", errors.New(fmt.Sprintf("empty version in %s", reference))
+		}
+	}
+	if name == "" {
+		return "", "", errors.New(fmt.Sprintf("empty component name in %s", reference))
+	}
+	if flagVersion != "" {
+		if version != "" && version != flagVersion {
+			return "", "", errors.New(fmt.Sprintf("version %s conflicts with --version %s", version, flagVersion))
+		}
+		version = flagVersion
+	}
+	return name, version, nil
+}
 
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// componentsInstallCmd.PersistentFlags().String("foo", "", "A help for foo")
+//newInstalledComponentVersion creates InstalledComponentVersion from repository.Component with single version
+func newInstalledComponentVersion(e *environment.Environment, c *repository.Component) environment.InstalledComponentVersion {
+	newComponent := environment.InstalledComponentVersion{
+		EnvironmentRef: e.Uuid,
+		Name:           c.Name,
+		Type:           c.Type,
+		Version:        c.Versions[0].Version,
+		Image:          c.Versions[0].Image,
+		WorkDirectory:  c.Versions[0].WorkDirectory,
+		Mounts:         c.Versions[0].Mounts,
+	}
+	for _, rc := range c.Versions[0].Commands {
+		nic := environment.InstalledComponentCommand{
+			Name:        rc.Name,
+			Description: rc.Description,
+			Command:     rc.Command,
+			Envs:        rc.Envs,
+			Args:        rc.Args,
+			Interactive: rc.Interactive,
+		}
+		newComponent.Commands = append(newComponent.Commands, nic)
+	}
+	return newComponent
+}
+
+//skipRepositoryVerification disables verification of repositories signatures if skip is set. It has to be called
+//before repository is loaded, so repositories which cannot be verified are not skipped.
+func skipRepositoryVerification(skip bool) {
+	if skip {
+		warnSkipVerification()
+		repository.SkipVerification()
+	}
+}
 
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// componentsInstallCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+//verifyComponent checks signature of repository component comes from (see repository.VerifyComponent)
+func verifyComponent(c *repository.Component) {
+	err := repository.VerifyComponent(c)
+	if err != nil {
+		errVerifyRepository(err)
+	}
 }
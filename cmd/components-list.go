@@ -1,8 +1,6 @@
 package cmd
 
 import (
-	"fmt"
-
 	"github.com/epiphany-platform/cli/pkg/repository"
 	"github.com/spf13/cobra"
 )
@@ -11,12 +9,18 @@ import (
 var componentsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "Lists all existing components in repository",
-	Long:  `TODO`,
+	Long: `Lists all versions of components available in repository.
+
+Usage: e components list
+
+Output format can be selected with global --output flag (text, json, yaml or template=<go template>). For formats
+other than text whole repository is printed.`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		debug("component list called")
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Print(repository.GetRepository().ComponentsString())
+		r := repository.GetRepository()
+		printOutput(r, r.ComponentsString())
 	},
 }
 
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/epiphany-platform/cli/pkg/environment"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	uninstallKeepData    bool
+	uninstallRemoveImage bool
+)
+
+// componentsUninstallCmd represents the uninstall command
+var componentsUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Uninstalls component from currently used environment",
+	Long: `Uninstalls component from currently used environment.
+
+Usage: e components uninstall <name> [version]
+
+Version is required only if there are multiple versions of component installed. By default
+runs and mounts directories of component version are removed as well.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("components uninstall called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 || len(args) > 2 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		var version string
+		if len(args) == 2 {
+			version = args[1]
+		}
+		e := getLockedEnvironment(cmd, args)
+		defer unlockEnvironment()
+
+		c, err := e.Uninstall(args[0], version)
+		if err != nil {
+			errUninstallComponent(err)
+		}
+		if !uninstallKeepData {
+			err = c.PurgeData()
+			if err != nil {
+				errPurgeComponentData(err)
+			}
+		}
+		if uninstallRemoveImage {
+			used, err := environment.IsImageUsed(c.Image)
+			if err != nil {
+				errCheckImageUsage(err)
+			}
+			if used {
+				infoImageStillUsed(c.Image)
+			} else {
+				err = c.RemoveImage()
+				if err != nil {
+					errRemoveImage(err)
+				}
+			}
+		}
+		fmt.Printf("Uninstalled component %s %s from environment %s\n", c.Name, c.Version, e.Name)
+	},
+}
+
+func init() {
+	componentsCmd.AddCommand(componentsUninstallCmd)
+
+	componentsUninstallCmd.Flags().BoolVar(&uninstallKeepData, "keep-data", false, "keep runs and mounts directories of uninstalled component")
+	componentsUninstallCmd.Flags().BoolVar(&uninstallRemoveImage, "remove-image", false, "remove component image if no other environment uses it")
+}
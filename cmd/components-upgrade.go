@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/epiphany-platform/cli/pkg/repository"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeTo                 string
+	upgradeFrom               string
+	upgradeMove               bool
+	upgradeInsecureSkipVerify bool
+)
+
+// componentsUpgradeCmd represents the upgrade command
+var componentsUpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrades component installed in currently used environment",
+	Long: `Upgrades component installed in currently used environment.
+
+Usage: e components upgrade <name> [--to version]
+
+New version is installed next to the previous one and mounts of previous version are copied (or moved with --move
+flag) into new version. By default version marked as latest is installed, but --to accepts exact version or semver
+range as well. Previous version stays installed and can be removed with "e components uninstall".`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("components upgrade called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		name, version, err := splitComponentReference(args[0], upgradeTo)
+		if err != nil {
+			errIncorrectComponentReference(err)
+		}
+		e := getLockedEnvironment(cmd, args)
+		defer unlockEnvironment()
+
+		skipRepositoryVerification(upgradeInsecureSkipVerify)
+		tc, err := repository.GetRepository().GetComponentByName(name)
+		if err != nil {
+			errGetComponentByName(err)
+		}
+		c, err := tc.JustVersion(version)
+		if err != nil {
+			errGetComponentWithVersion(err)
+		}
+		verifyComponent(c)
+
+		newComponent := newInstalledComponentVersion(e, c)
+		previous, err := e.Upgrade(newComponent, upgradeFrom, upgradeMove)
+		if err != nil {
+			errUpgradeComponent(err)
+		}
+		fmt.Printf("Upgraded component %s from %s to %s in environment %s\n", newComponent.Name, previous.Version, newComponent.Version, e.Name)
+	},
+}
+
+func init() {
+	componentsCmd.AddCommand(componentsUpgradeCmd)
+
+	componentsUpgradeCmd.Flags().StringVar(&upgradeTo, "to", "", "version or semver range to upgrade to (default is version marked latest)")
+	componentsUpgradeCmd.Flags().StringVar(&upgradeFrom, "from", "", "installed version to upgrade from (default is the most recently installed one)")
+	componentsUpgradeCmd.Flags().BoolVar(&upgradeMove, "move", false, "move mounts of previous version instead of copying them")
+	componentsUpgradeCmd.Flags().BoolVar(&upgradeInsecureSkipVerify, "insecure-skip-verify", false, "do not verify signature of repository component comes from")
+}
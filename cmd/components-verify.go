@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/epiphany-platform/cli/pkg/environment"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyPull bool
+)
+
+// componentsVerifyCmd represents the verify command
+var componentsVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verifies images of installed components",
+	Long: `Verifies if image tags of components installed in currently used environment still point to image digests
+recorded at install time. Components are always run by recorded digest, so drift means that tag was moved in
+registry after installation.
+
+Usage: e components verify [name]
+
+By default local images are checked. With --pull flag image tags are pulled first to check what registry currently
+serves. Status of each component is one of: ok, drift, unpinned (no digest recorded, i.e. installed with older
+version of e) or unknown (tag digest cannot be determined, i.e. image is not present locally or was imported from
+bundle). Command fails if drift is found. Output format can be selected with global --output flag (text, json, yaml
+or template=<go template>).`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("components verify called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		e := getUsedEnvironment()
+		verifications := make([]*environment.ImageVerification, 0, len(e.Installed))
+		for i := range e.Installed {
+			cv := &e.Installed[i]
+			if len(args) == 1 && cv.Name != args[0] {
+				continue
+			}
+			v, err := cv.VerifyImage(verifyPull)
+			if err != nil {
+				errVerifyImage(err)
+			}
+			verifications = append(verifications, v)
+		}
+		if len(args) == 1 && len(verifications) == 0 {
+			errGetComponentByName(errors.New("no such component installed"))
+		}
+		printOutput(verifications, verificationsTable(verifications))
+		var drifted []string
+		for _, v := range verifications {
+			if v.Status == environment.ImageStatusDrift {
+				drifted = append(drifted, fmt.Sprintf("%s %s", v.Name, v.Version))
+			}
+		}
+		if len(drifted) > 0 {
+			errImageDrift(errors.New(fmt.Sprintf("image tags of components %s point to other digests than installed ones", strings.Join(drifted, ", "))))
+		}
+	},
+}
+
+func init() {
+	componentsCmd.AddCommand(componentsVerifyCmd)
+
+	componentsVerifyCmd.Flags().BoolVar(&verifyPull, "pull", false, "pull image tags before verification to check what registry currently serves")
+}
+
+// verificationsTable formats image verifications as text table
+func verificationsTable(verifications []*environment.ImageVerification) string {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NAME\tVERSION\tIMAGE\tDIGEST\tCURRENT DIGEST\tSTATUS")
+	for _, v := range verifications {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", v.Name, v.Version, v.Image, shortDigest(v.Digest), shortDigest(v.CurrentDigest), v.Status)
+	}
+	_ = w.Flush()
+	return b.String()
+}
+
+// shortDigest shortens digest to algorithm and first 12 characters of hash
+func shortDigest(digest string) string {
+	if digest == "" {
+		return "-"
+	}
+	if i := strings.Index(digest, ":"); i >= 0 && len(digest) > i+13 {
+		return digest[:i+13]
+	}
+	return digest
+}
@@ -11,7 +11,11 @@ var componentsCmd = &cobra.Command{
 	Long: `This command provides way to:
  - list available components, 
  - install new component to environment
+ - uninstall component from environment
+ - upgrade component installed in environment
  - get information about component
+ - export component with its image to bundle file and import it on another machine
+ - verify images of installed components against digests recorded at install time
 
 Information about available components are taken from https://github.com/mkyc/epiphany-wrapper-poc-repo/blob/master/v1.yaml`,
 	PreRun: func(cmd *cobra.Command, args []string) {
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/epiphany-platform/cli/pkg/environment"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cloneWithMounts bool
+)
+
+// environmentsCloneCmd represents the clone command
+var environmentsCloneCmd = &cobra.Command{
+	Use:   "clone",
+	Short: "Creates copy of environment",
+	Long: `Creates copy of environment with new UUID and all components installed in source environment.
+
+Usage: e environments clone <source> <new-name>
+
+Source environment can be provided as name, full UUID or unique UUID prefix.
+
+Runs history is not copied. Content of mounts directories of components is copied only with --with-mounts flag.
+Currently used environment is not changed.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("environments clone called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		source, err := environment.Find(args[0])
+		if err != nil {
+			errGetEnvironmentDetails(err)
+		}
+		clone, err := source.Clone(args[1], cloneWithMounts)
+		if err != nil {
+			errCloneEnvironment(err)
+		}
+		fmt.Printf("Cloned environment %s into %s (%s)\n", source.Name, clone.Name, clone.Uuid.String())
+	},
+}
+
+func init() {
+	environmentsCmd.AddCommand(environmentsCloneCmd)
+
+	environmentsCloneCmd.Flags().BoolVar(&cloneWithMounts, "with-mounts", false, "copy content of mounts directories of components")
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/epiphany-platform/cli/pkg/configuration"
+	"github.com/epiphany-platform/cli/pkg/environment"
+	"github.com/epiphany-platform/cli/pkg/promptui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	deleteForce        bool
+	deleteRemoveImages bool
+)
+
+// environmentsDeleteCmd represents the delete command
+var environmentsDeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Deletes environment",
+	Long: `Deletes environment with all installed components data (runs and mounts directories).
+
+Usage: e environments delete [environment]
+
+Environment can be provided as name, full UUID or unique UUID prefix. By default currently used environment is
+deleted. If deleted environment is currently used one, there is no environment used after deletion. Confirmation is
+required unless --force flag is provided. With --remove-images flag images of installed components which are not
+used by any other environment are removed as well.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("environments delete called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		config, err := configuration.GetConfig()
+		if err != nil {
+			errGetConfig(err)
+		}
+		var e *environment.Environment
+		if len(args) == 1 {
+			e, err = environment.Find(args[0])
+			if err != nil {
+				errGetEnvironmentDetails(err)
+			}
+		} else {
+			e = getUsedEnvironment()
+		}
+		e = lockEnvironment(e, cmd, args)
+		defer unlockEnvironment()
+
+		if !deleteForce {
+			confirmed, err := promptui.PromptForConfirmation(fmt.Sprintf("Delete environment %s (%s)", e.Name, e.Uuid.String()))
+			if err != nil {
+				errPrompt(err)
+			}
+			if !confirmed {
+				fmt.Println("Aborted")
+				return
+			}
+		}
+
+		err = config.DeleteEnvironment(e)
+		if err != nil {
+			errDeleteEnvironment(err)
+		}
+		if deleteRemoveImages {
+			removed := make(map[string]bool)
+			for _, ic := range e.Installed {
+				if removed[ic.Image] {
+					continue
+				}
+				removed[ic.Image] = true
+				used, err := environment.IsImageUsed(ic.Image)
+				if err != nil {
+					errCheckImageUsage(err)
+				}
+				if used {
+					infoImageStillUsed(ic.Image)
+					continue
+				}
+				err = ic.RemoveImage()
+				if err != nil {
+					warnRemoveImage(err, ic.Image)
+				}
+			}
+		}
+		fmt.Printf("Deleted environment %s (%s)\n", e.Name, e.Uuid.String())
+	},
+}
+
+func init() {
+	environmentsCmd.AddCommand(environmentsDeleteCmd)
+
+	environmentsDeleteCmd.Flags().BoolVarP(&deleteForce, "force", "f", false, "do not ask for confirmation")
+	environmentsDeleteCmd.Flags().BoolVar(&deleteRemoveImages, "remove-images", false, "remove images of installed components if no other environment uses them")
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/epiphany-platform/cli/pkg/environment"
+	"github.com/spf13/cobra"
+)
+
+var (
+	environmentExportOutput      string
+	environmentExportIncludeRuns bool
+)
+
+// environmentsExportCmd represents the export command
+var environmentsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports environment to archive file",
+	Long: `Exports environment config and mounts directories of all installed components to gzipped tar archive which
+can be imported with "e environments import" on another machine.
+
+Usage: e environments export [environment] [-o env.tar.gz]
+
+Environment can be provided as name, full UUID or unique UUID prefix. By default currently used environment is
+exported. Runs history is included only with --include-runs flag. Default output file is <name>.tar.gz. Here
+--output (-o) flag selects output file instead of output format.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("environments export called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		var e *environment.Environment
+		if len(args) == 1 {
+			var err error
+			e, err = environment.Find(args[0])
+			if err != nil {
+				errGetEnvironmentDetails(err)
+			}
+		} else {
+			e = getUsedEnvironment()
+		}
+
+		output := environmentExportOutput
+		if output == "" {
+			output = fmt.Sprintf("%s.tar.gz", e.Name)
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			errExportEnvironment(err)
+		}
+		err = e.Export(f, environmentExportIncludeRuns)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(output)
+			errExportEnvironment(err)
+		}
+		fmt.Printf("Exported environment %s (%s) to %s\n", e.Name, e.Uuid.String(), output)
+	},
+}
+
+func init() {
+	environmentsCmd.AddCommand(environmentsExportCmd)
+
+	environmentsExportCmd.Flags().StringVarP(&environmentExportOutput, "output", "o", "", "archive file to write (default is <name>.tar.gz)")
+	environmentsExportCmd.Flags().BoolVar(&environmentExportIncludeRuns, "include-runs", false, "include runs history of components")
+}
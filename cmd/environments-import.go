@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/epiphany-platform/cli/pkg/environment"
+	"github.com/epiphany-platform/cli/pkg/promptui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	environmentImportRekey bool
+)
+
+// environmentsImportCmd represents the import command
+var environmentsImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Imports environment from archive file",
+	Long: `Imports environment from archive file created with "e environments export". Integrity of all archived files is
+verified before environment is created.
+
+Usage: e environments import <env.tar.gz>
+
+If environment with the same UUID already exists, you are asked if environment should be imported with new UUID.
+Use --rekey flag to do that without asking. Currently used environment is not changed.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("environments import called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		e, err := importEnvironment(args[0], environmentImportRekey)
+		if err == environment.ErrUUIDCollision {
+			confirmed, promptErr := promptui.PromptForConfirmation("Environment with the same UUID already exists. Import it with new UUID")
+			if promptErr != nil {
+				errPrompt(promptErr)
+			}
+			if !confirmed {
+				fmt.Println("Aborted")
+				return
+			}
+			e, err = importEnvironment(args[0], true)
+		}
+		if err != nil {
+			errImportEnvironment(err)
+		}
+		fmt.Printf("Imported environment %s (%s)\n", e.Name, e.Uuid.String())
+	},
+}
+
+func init() {
+	environmentsCmd.AddCommand(environmentsImportCmd)
+
+	environmentsImportCmd.Flags().BoolVar(&environmentImportRekey, "rekey", false, "import environment with new UUID if its UUID is already used")
+}
+
+// importEnvironment imports environment from archive file
+func importEnvironment(file string, rekey bool) (*environment.Environment, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return environment.Import(f, rekey)
+}
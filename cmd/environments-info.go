@@ -1,11 +1,6 @@
 package cmd
 
 import (
-	"fmt"
-
-	"github.com/epiphany-platform/cli/pkg/configuration"
-	"github.com/epiphany-platform/cli/pkg/environment"
-	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 )
 
@@ -13,23 +8,17 @@ import (
 var environmentsInfoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Displays information about currently selected environment",
-	Long:  `TODO`,
+	Long: `Displays information about currently selected environment and components installed in it.
+
+Usage: e environments info
+
+Output format can be selected with global --output flag (text, json, yaml or template=<go template>).`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		debug("environments info called")
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		config, err := configuration.GetConfig()
-		if err != nil {
-			errGetConfig(err)
-		}
-		if config.CurrentEnvironment == uuid.Nil {
-			errNilEnvironment()
-		}
-		environment, err := environment.Get(config.CurrentEnvironment)
-		if err != nil {
-			errGetEnvironmentDetails(err)
-		}
-		fmt.Print(environment.String())
+		e := getUsedEnvironment()
+		printOutput(e, e.String())
 	},
 }
 
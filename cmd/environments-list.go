@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/epiphany-platform/cli/pkg/configuration"
+	"github.com/epiphany-platform/cli/pkg/environment"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// environmentSummary is single row of environments list
+type environmentSummary struct {
+	Current    bool       `yaml:"current" json:"current"`
+	Name       string     `yaml:"name" json:"name"`
+	Uuid       uuid.UUID  `yaml:"uuid" json:"uuid"`
+	Components int        `yaml:"components" json:"components"`
+	LastRun    *time.Time `yaml:"last_run,omitempty" json:"last_run,omitempty"`
+}
+
+// environmentsListCmd represents the list command
+var environmentsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists all environments",
+	Long: `Lists all environments with number of installed components and time of last run. Currently used environment
+is marked with *.
+
+Usage: e environments list
+
+Last run time takes into account only runs executed on this machine. Output format can be selected with global
+--output flag (text, json, yaml or template=<go template>).`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("environments list called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := configuration.GetConfig()
+		if err != nil {
+			errGetConfig(err)
+		}
+		environments, err := environment.GetAll()
+		if err != nil {
+			errGetEnvironments(err)
+		}
+		sort.SliceStable(environments, func(i, j int) bool {
+			return environments[i].Name < environments[j].Name
+		})
+		summaries := make([]environmentSummary, 0, len(environments))
+		for _, e := range environments {
+			s := environmentSummary{
+				Current:    e.Uuid == config.CurrentEnvironment,
+				Name:       e.Name,
+				Uuid:       e.Uuid,
+				Components: len(e.Installed),
+			}
+			lastRun, err := e.LastRun()
+			if err != nil {
+				warnGetLastRun(err, e.Uuid.String())
+			} else if !lastRun.IsZero() {
+				s.LastRun = &lastRun
+			}
+			summaries = append(summaries, s)
+		}
+		printOutput(summaries, environmentsTable(summaries))
+	},
+}
+
+func init() {
+	environmentsCmd.AddCommand(environmentsListCmd)
+}
+
+// environmentsTable formats environment summaries as text table
+func environmentsTable(summaries []environmentSummary) string {
+	var b bytes.Buffer
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "CURRENT\tNAME\tUUID\tCOMPONENTS\tLAST RUN")
+	for _, s := range summaries {
+		current, lastRun := "", "-"
+		if s.Current {
+			current = "*"
+		}
+		if s.LastRun != nil {
+			lastRun = s.LastRun.Format("2006-01-02 15:04:05")
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", current, s.Name, s.Uuid.String(), s.Components, lastRun)
+	}
+	_ = w.Flush()
+	return b.String()
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// environmentsRenameCmd represents the rename command
+var environmentsRenameCmd = &cobra.Command{
+	Use:   "rename",
+	Short: "Renames currently used environment",
+	Long: `Renames currently used environment.
+
+Usage: e environments rename <new-name>`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("environments rename called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		e := getLockedEnvironment(cmd, args)
+		defer unlockEnvironment()
+		oldName := e.Name
+		err := e.Rename(args[0])
+		if err != nil {
+			errRenameEnvironment(err)
+		}
+		fmt.Printf("Renamed environment %s to %s\n", oldName, e.Name)
+	},
+}
+
+func init() {
+	environmentsCmd.AddCommand(environmentsRenameCmd)
+}
@@ -3,37 +3,70 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"time"
 
-	"github.com/epiphany-platform/cli/pkg/configuration"
 	"github.com/epiphany-platform/cli/pkg/environment"
+	"github.com/epiphany-platform/cli/pkg/runtime"
+	"github.com/epiphany-platform/cli/pkg/util"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	runInteractive bool
+	runEnvs        []string
+	runEnvFile     string
+	runTimeout     time.Duration
+	runGracePeriod time.Duration
+)
+
 // environmentsRunCmd represents the run command
 var environmentsRunCmd = &cobra.Command{ //TODO consider what are options to create integration tests here. For me it seams that it would be testing of docker
 	Use:   "run",
 	Short: "Runs installed component command in environment",
-	Long:  `TODO`,
+	Long: `Runs installed component command in environment.
+
+Usage: e environments run <component> <command> [-- extra args]
+
+Arguments provided after -- are appended to arguments of component command. Environment variables of component
+command can be overridden with --env-file and -e KEY=VALUE flags (the latter takes precedence).
+
+Exit status of e is the exit code of component container. SIGINT and SIGTERM received by e are forwarded to
+container. If container doesn't finish within --grace-period after that (or after --timeout is exceeded) it is
+killed. Container is always removed after run.
+
+With --interactive flag (or when component command is marked as interactive) container gets TTY and local stdin
+attached, so commands asking for confirmation can be used. In this mode stdout and stderr of container are merged.`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		debug("environments run called")
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) == 2 {
-			config, err := configuration.GetConfig()
-			if err != nil {
-				errGetConfig(err)
-			}
-			e, err := environment.Get(config.CurrentEnvironment)
+		extraArgs, err := getExtraArgs(cmd, args)
+		if err != nil {
+			errIncorrectNumberOfArguments(err)
+		}
+		if len(args)-len(extraArgs) == 2 {
+			envs, err := getRunEnvs()
 			if err != nil {
-				errGetEnvironmentDetails(err)
+				errIncorrectEnvironmentVariables(err)
 			}
+			e := getLockedEnvironment(cmd, args)
+			defer unlockEnvironment()
 			c, err := e.GetComponentByName(args[0])
 			if err != nil {
 				errGetComponentByName(err)
 			}
-			err = c.Run(args[1])
-			if err != nil {
+			err = c.Run(args[1], environment.RunOptions{
+				Interactive: runInteractive,
+				Args:        extraArgs,
+				Envs:        envs,
+				Timeout:     runTimeout,
+				GracePeriod: runGracePeriod,
+			})
+			var exitErr *runtime.ExitError
+			if errors.As(err, &exitErr) {
+				errRunCommandExit(err, exitErr.ExitCode)
+			} else if err != nil {
 				errRunCommand(err)
 			}
 			infoRunFinished(args[0], args[1])
@@ -45,4 +78,44 @@ var environmentsRunCmd = &cobra.Command{ //TODO consider what are options to cre
 
 func init() {
 	environmentsCmd.AddCommand(environmentsRunCmd)
+
+	environmentsRunCmd.Flags().BoolVarP(&runInteractive, "interactive", "i", false, "allocate TTY and attach stdin to component container")
+	environmentsRunCmd.Flags().StringArrayVarP(&runEnvs, "env", "e", nil, "set environment variable of component command (KEY=VALUE)")
+	environmentsRunCmd.Flags().StringVar(&runEnvFile, "env-file", "", "read environment variables of component command from file")
+	environmentsRunCmd.Flags().DurationVar(&runTimeout, "timeout", 0, "stop component container after this time (e.g. 30m, default is no timeout)")
+	environmentsRunCmd.Flags().DurationVar(&runGracePeriod, "grace-period", 10*time.Second, "time to wait for container to finish after stopping it before killing it")
+}
+
+//getExtraArgs returns arguments provided after -- separator
+func getExtraArgs(cmd *cobra.Command, args []string) ([]string, error) {
+	dash := cmd.ArgsLenAtDash()
+	if dash < 0 {
+		return nil, nil
+	}
+	if dash != 2 {
+		return nil, errors.New(fmt.Sprintf("expected 2 args before -- but found %d", dash))
+	}
+	return args[dash:], nil
+}
+
+//getRunEnvs merges environment variables from --env-file and --env flags
+func getRunEnvs() (map[string]string, error) {
+	envs := make(map[string]string)
+	if runEnvFile != "" {
+		fileEnvs, err := util.ReadEnvironmentFile(runEnvFile)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range fileEnvs {
+			envs[k] = v
+		}
+	}
+	for _, kv := range runEnvs {
+		k, v, err := util.ParseEnvironmentVariable(kv)
+		if err != nil {
+			return nil, err
+		}
+		envs[k] = v
+	}
+	return envs, nil
 }
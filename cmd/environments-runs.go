@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// environmentsRunsCmd represents the runs command
+var environmentsRunsCmd = &cobra.Command{
+	Use:   "runs",
+	Short: "Lists and displays logs of past component runs",
+	Long: `Lists and displays logs of past component runs stored in runs directory of installed component.
+
+Usage: e environments runs <component> [run]
+
+Without run argument it lists all logs of all installed versions of component. With run argument it displays
+content of selected log.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("environments runs called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) < 1 || len(args) > 2 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		e := getUsedEnvironment()
+		found := false
+		for _, ic := range e.Installed {
+			if ic.Name != args[0] {
+				continue
+			}
+			found = true
+			runs, err := ic.GetRuns()
+			if err != nil {
+				errGetRuns(err)
+			}
+			for _, r := range runs {
+				if len(args) == 1 {
+					fmt.Printf("%s %s\n", ic.Version, r)
+				} else if r == args[1] {
+					content, err := ic.GetRunLog(r)
+					if err != nil {
+						errGetRuns(err)
+					}
+					fmt.Print(content)
+					return
+				}
+			}
+		}
+		if !found {
+			errGetComponentByName(errors.New("no such component installed"))
+		}
+		if len(args) == 2 {
+			errGetRuns(errors.New(fmt.Sprintf("no such run: %s", args[1])))
+		}
+	},
+}
+
+func init() {
+	environmentsCmd.AddCommand(environmentsRunsCmd)
+}
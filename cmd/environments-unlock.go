@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/epiphany-platform/cli/pkg/environment"
+	"github.com/epiphany-platform/cli/pkg/promptui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	unlockForce bool
+)
+
+// environmentsUnlockCmd represents the unlock command
+var environmentsUnlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Removes lock of environment",
+	Long: `Removes lock of environment left by command which didn't finish properly.
+
+Usage: e environments unlock [environment]
+
+Environment can be provided as name, full UUID or unique UUID prefix. By default currently used environment is
+unlocked. Commands changing environment (like components install or environments run) hold its lock until they
+finish, so lock should be removed only if its holder is not running anymore. Confirmation is required unless
+--force flag is provided.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("environments unlock called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		var e *environment.Environment
+		var err error
+		if len(args) == 1 {
+			e, err = environment.Find(args[0])
+			if err != nil {
+				errGetEnvironmentDetails(err)
+			}
+		} else {
+			e = getUsedEnvironment()
+		}
+		l, err := e.GetLock()
+		if err != nil {
+			errUnlockEnvironment(err)
+		}
+		if l == nil {
+			fmt.Printf("Environment %s (%s) is not locked\n", e.Name, e.Uuid.String())
+			return
+		}
+		if !unlockForce {
+			confirmed, err := promptui.PromptForConfirmation(fmt.Sprintf("Remove lock held by %s", l.String()))
+			if err != nil {
+				errPrompt(err)
+			}
+			if !confirmed {
+				fmt.Println("Aborted")
+				return
+			}
+		}
+		err = e.Unlock()
+		if err != nil {
+			errUnlockEnvironment(err)
+		}
+		fmt.Printf("Unlocked environment %s (%s)\n", e.Name, e.Uuid.String())
+	},
+}
+
+func init() {
+	environmentsCmd.AddCommand(environmentsUnlockCmd)
+
+	environmentsUnlockCmd.Flags().BoolVarP(&unlockForce, "force", "f", false, "do not ask for confirmation")
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"github.com/epiphany-platform/cli/pkg/configuration"
+	"github.com/epiphany-platform/cli/pkg/environment"
 	"github.com/epiphany-platform/cli/pkg/promptui"
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
@@ -11,7 +12,12 @@ import (
 var environmentsUseCmd = &cobra.Command{
 	Use:   "use",
 	Short: "Allows to select environment to be used",
-	Long:  `TODO`,
+	Long: `Allows to select environment to be used.
+
+Usage: e environments use [environment]
+
+Environment can be provided as name, full UUID or unique UUID prefix. Without argument environment is selected
+from list.`,
 	PreRun: func(cmd *cobra.Command, args []string) {
 		debug("environments use called")
 	},
@@ -20,13 +26,13 @@ var environmentsUseCmd = &cobra.Command{
 		if err != nil {
 			errGetConfig(err)
 		}
-		if config.CurrentEnvironment == uuid.Nil {
-			errNilEnvironment()
-		}
-
 		var u uuid.UUID
 		if len(args) == 1 {
-			u = uuid.MustParse(args[0])
+			e, err := environment.Find(args[0])
+			if err != nil {
+				errGetEnvironmentDetails(err)
+			}
+			u = e.Uuid
 		} else {
 			u, err = promptui.PromptForEnvironmentSelect("Environments")
 			if err != nil {
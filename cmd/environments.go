@@ -1,9 +1,18 @@
 package cmd
 
 import (
+	"strings"
+
+	"github.com/epiphany-platform/cli/pkg/configuration"
+	"github.com/epiphany-platform/cli/pkg/environment"
+	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 )
 
+var (
+	lockedEnvironment *environment.Environment
+)
+
 // environmentsCmd represents the environments command
 var environmentsCmd = &cobra.Command{
 	Use:   "environments",
@@ -17,3 +26,62 @@ var environmentsCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(environmentsCmd)
 }
+
+// getUsedEnvironment returns environment selected with global --environment flag or (if flag is not provided)
+// environment currently used according to configuration
+func getUsedEnvironment() *environment.Environment {
+	if environmentReference != "" {
+		e, err := environment.Find(environmentReference)
+		if err != nil {
+			errGetEnvironmentDetails(err)
+		}
+		return e
+	}
+	config, err := configuration.GetConfig()
+	if err != nil {
+		errGetConfig(err)
+	}
+	if config.CurrentEnvironment == uuid.Nil {
+		errNilEnvironment()
+	}
+	e, err := environment.Get(config.CurrentEnvironment)
+	if err != nil {
+		errGetEnvironmentDetails(err)
+	}
+	return e
+}
+
+// getLockedEnvironment returns environment selected like in getUsedEnvironment after acquiring its lock
+func getLockedEnvironment(cmd *cobra.Command, args []string) *environment.Environment {
+	return lockEnvironment(getUsedEnvironment(), cmd, args)
+}
+
+// lockEnvironment acquires lock of environment for executed command and returns environment read again after
+// acquiring lock, so changes made by previous lock holder are not lost. Lock is released by unlockEnvironment
+// which is also called before exit on fatal errors.
+func lockEnvironment(e *environment.Environment, cmd *cobra.Command, args []string) *environment.Environment {
+	command := strings.Join(append([]string{cmd.CommandPath()}, args...), " ")
+	err := e.Lock(command)
+	if err != nil {
+		errLockEnvironment(err)
+	}
+	lockedEnvironment = e
+	locked, err := environment.Get(e.Uuid)
+	if err != nil {
+		errGetEnvironmentDetails(err)
+	}
+	return locked
+}
+
+// unlockEnvironment releases lock acquired with lockEnvironment
+func unlockEnvironment() {
+	if lockedEnvironment == nil {
+		return
+	}
+	e := lockedEnvironment
+	lockedEnvironment = nil
+	err := e.Unlock()
+	if err != nil {
+		warnUnlockEnvironment(err, e.Uuid.String())
+	}
+}
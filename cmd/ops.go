@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"os"
+
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -12,7 +14,17 @@ var (
 func init() {
 	logger = log.With().
 		Str("package", "cmd").
-		Logger()
+		Logger().
+		Hook(unlockHook{})
+}
+
+// unlockHook releases environment lock before fatal error exits program
+type unlockHook struct{}
+
+func (h unlockHook) Run(_ *zerolog.Event, level zerolog.Level, _ string) {
+	if level == zerolog.FatalLevel {
+		unlockEnvironment()
+	}
 }
 
 func debug(format string, v ...interface{}) {
@@ -56,6 +68,20 @@ func errGetComponentWithLatestVersion(err error) {
 		Msg("getting component with latest version failed")
 }
 
+func errGetComponentWithVersion(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("getting component with requested version failed")
+}
+
+func errIncorrectComponentReference(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("incorrect component reference")
+}
+
 func errTooFewArguments(err error) {
 	logger.
 		Fatal().
@@ -84,6 +110,69 @@ func errInstallComponent(err error) {
 		Msg("install component in environment failed")
 }
 
+func errUpgradeComponent(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("upgrade component in environment failed")
+}
+
+func errUninstallComponent(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("uninstall component from environment failed")
+}
+
+func errPurgeComponentData(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("removing component data failed")
+}
+
+func errCheckImageUsage(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("checking image usage failed")
+}
+
+func errRemoveImage(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("removing image failed")
+}
+
+func errGetRuntime(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("getting container runtime failed")
+}
+
+func errPullImage(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("pulling image failed")
+}
+
+func errExportComponent(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("exporting component failed")
+}
+
+func errImportComponent(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("importing component failed")
+}
+
 func errNilEnvironment() {
 	logger.
 		Fatal().
@@ -104,6 +193,41 @@ func errPrompt(err error) {
 		Msg("prompt failed")
 }
 
+func errDeleteEnvironment(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("delete environment failed")
+}
+
+func errRenameEnvironment(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("rename environment failed")
+}
+
+func errCloneEnvironment(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("clone environment failed")
+}
+
+func errExportEnvironment(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("export environment failed")
+}
+
+func errImportEnvironment(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("import environment failed")
+}
+
 func errCreateEnvironment(err error) {
 	logger.
 		Fatal().
@@ -118,6 +242,43 @@ func errRunCommand(err error) {
 		Msg("run command failed")
 }
 
+func errGetRuns(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("getting component runs failed")
+}
+
+func errIncorrectEnvironmentVariables(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("incorrect environment variables")
+}
+
+func errRunCommandExit(err error, exitCode int) {
+	logger.
+		Error().
+		Err(err).
+		Msg("run command failed")
+	unlockEnvironment()
+	os.Exit(exitCode)
+}
+
+func errRegistryLogin(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("registry login failed")
+}
+
+func errRegistryLogout(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("registry logout failed")
+}
+
 func errSetEnvironment(err error) {
 	logger.
 		Fatal().
@@ -125,6 +286,13 @@ func errSetEnvironment(err error) {
 		Msg("setting used environment failed")
 }
 
+func warnRemoveImage(err error, image string) {
+	logger.
+		Warn().
+		Err(err).
+		Msgf("removing image %s failed", image)
+}
+
 func infoConfigFile(filePath string) {
 	logger.
 		Info().
@@ -137,8 +305,130 @@ func infoRunFinished(component string, command string) {
 		Msgf("running %s %s finished", component, command)
 }
 
+func infoImageStillUsed(image string) {
+	logger.
+		Info().
+		Msgf("image %s is still used by other environment, will not remove it", image)
+}
+
+func infoLoggedIn(server string) {
+	logger.
+		Info().
+		Msgf("logged in to %s", server)
+}
+
+func infoLoggedOut(server string) {
+	logger.
+		Info().
+		Msgf("logged out from %s", server)
+}
+
 func infoChosenEnvironment(uuid string) {
 	logger.
 		Info().
 		Msgf("Chosen environment UUID is %s", uuid)
 }
+
+func errLockEnvironment(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("locking environment failed")
+}
+
+func errUnlockEnvironment(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("unlocking environment failed")
+}
+
+func warnUnlockEnvironment(err error, uuid string) {
+	logger.
+		Warn().
+		Err(err).
+		Msgf("releasing lock of environment %s failed", uuid)
+}
+
+func errPrintOutput(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("printing output failed")
+}
+
+func errIncorrectOutputFormat(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("incorrect output format")
+}
+
+func warnGetLastRun(err error, uuid string) {
+	logger.
+		Warn().
+		Err(err).
+		Msgf("getting last run of environment %s failed", uuid)
+}
+
+func errAddRepository(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("adding repository failed")
+}
+
+func errRemoveRepository(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("removing repository failed")
+}
+
+func errUpdateRepository(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("updating repository failed")
+}
+
+func warnUpdateRepository(err error, name string) {
+	logger.
+		Warn().
+		Err(err).
+		Msgf("cannot update repository %s", name)
+}
+
+func errLintRepository(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("linting repository failed")
+}
+
+func errVerifyRepository(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("repository verification failed, use --insecure-skip-verify to skip it")
+}
+
+func warnSkipVerification() {
+	logger.
+		Warn().
+		Msg("verification of repositories signatures is skipped")
+}
+
+func errVerifyImage(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("image verification failed")
+}
+
+func errImageDrift(err error) {
+	logger.
+		Fatal().
+		Err(err).
+		Msg("image drift found")
+}
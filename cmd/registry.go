@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/epiphany-platform/cli/pkg/promptui"
+	"github.com/epiphany-platform/cli/pkg/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	registryUsername      string
+	registryPassword      string
+	registryPasswordStdin bool
+)
+
+// registryCmd represents the registry command
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Allows to manage credentials of container registries",
+	Long: `This command provides way to:
+ - login to container registry
+ - logout from container registry
+
+Credentials are used when component images are pulled. If there are no credentials stored for registry in
+configuration directory, credentials from docker config (~/.docker/config.json or $DOCKER_CONFIG) are used,
+including configured credential helpers.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("registry called")
+	},
+}
+
+// registryLoginCmd represents the login command
+var registryLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Stores credentials of container registry",
+	Long: `Stores credentials of container registry in configuration directory.
+
+Usage: e registry login <server> -u <username> [-p <password> | --password-stdin]
+
+If password is not provided it is prompted for.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("registry login called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		password, err := getRegistryPassword()
+		if err != nil {
+			errPrompt(err)
+		}
+		err = registry.Login(registry.Credentials{
+			Server:   args[0],
+			Username: registryUsername,
+			Password: password,
+		})
+		if err != nil {
+			errRegistryLogin(err)
+		}
+		infoLoggedIn(args[0])
+	},
+}
+
+// registryLogoutCmd represents the logout command
+var registryLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Removes stored credentials of container registry",
+	Long: `Removes credentials of container registry from configuration directory.
+
+Usage: e registry logout <server>`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("registry logout called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		err := registry.Logout(args[0])
+		if err != nil {
+			errRegistryLogout(err)
+		}
+		infoLoggedOut(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(registryCmd)
+	registryCmd.AddCommand(registryLoginCmd)
+	registryCmd.AddCommand(registryLogoutCmd)
+
+	registryLoginCmd.Flags().StringVarP(&registryUsername, "username", "u", "", "registry username")
+	registryLoginCmd.Flags().StringVarP(&registryPassword, "password", "p", "", "registry password")
+	registryLoginCmd.Flags().BoolVar(&registryPasswordStdin, "password-stdin", false, "read registry password from stdin")
+	_ = registryLoginCmd.MarkFlagRequired("username")
+}
+
+// getRegistryPassword returns password from flags, stdin or prompt
+func getRegistryPassword() (string, error) {
+	if registryPasswordStdin {
+		if registryPassword != "" {
+			return "", errors.New("--password and --password-stdin are mutually exclusive")
+		}
+		b, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(b), "\r\n"), nil
+	}
+	if registryPassword != "" {
+		return registryPassword, nil
+	}
+	return promptui.PromptForPassword("Password")
+}
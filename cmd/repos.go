@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/epiphany-platform/cli/pkg/configuration"
+	"github.com/epiphany-platform/cli/pkg/repository"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reposPriority int
+)
+
+// reposCmd represents the repos command
+var reposCmd = &cobra.Command{
+	Use:   "repos",
+	Short: "Allows to manage component repositories",
+	Long: `This command provides way to:
+ - add component repository
+ - remove component repository
+ - list component repositories
+ - update cached component repositories
+ - lint component repository file
+
+Components of all repositories are merged. If there are components with the same name in multiple repositories, the
+one from repository with the highest priority is used, and other ones can be referenced with name qualified with
+repository name (like internal/c1).`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("repos called")
+	},
+}
+
+// reposAddCmd represents the add command
+var reposAddCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Adds component repository",
+	Long: `Adds component repository.
+
+Usage: e repos add <name> <url or path> [--priority <priority>]
+
+Repository can be http(s) URL of v1.yaml file (which is downloaded and cached in configuration directory) or path
+to local file. When first repository is added, default repository is kept next to it.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("repos add called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 2 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		config, err := configuration.GetConfig()
+		if err != nil {
+			errGetConfig(err)
+		}
+		url := args[1]
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			url, err = filepath.Abs(strings.TrimPrefix(url, "file://"))
+			if err != nil {
+				errAddRepository(err)
+			}
+		}
+		err = config.AddRepository(repository.Source{
+			Name:     args[0],
+			Url:      url,
+			Priority: reposPriority,
+		})
+		if err != nil {
+			errAddRepository(err)
+		}
+		fmt.Printf("Added repository %s (%s)\n", args[0], url)
+	},
+}
+
+// reposRemoveCmd represents the remove command
+var reposRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Removes component repository",
+	Long: `Removes component repository and its cached file.
+
+Usage: e repos remove <name>
+
+The last repository cannot be removed.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("repos remove called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		config, err := configuration.GetConfig()
+		if err != nil {
+			errGetConfig(err)
+		}
+		err = config.RemoveRepository(args[0])
+		if err != nil {
+			errRemoveRepository(err)
+		}
+		fmt.Printf("Removed repository %s\n", args[0])
+	},
+}
+
+// reposListCmd represents the list command
+var reposListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists component repositories",
+	Long: `Lists component repositories from the highest priority.
+
+Usage: e repos list
+
+Output format can be selected with global --output flag (text, json, yaml or template=<go template>).`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("repos list called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		sources := repository.Sources()
+		var b bytes.Buffer
+		w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "NAME\tPRIORITY\tURL")
+		for _, s := range sources {
+			_, _ = fmt.Fprintf(w, "%s\t%d\t%s\n", s.Name, s.Priority, s.Url)
+		}
+		_ = w.Flush()
+		printOutput(sources, b.String())
+	},
+}
+
+// reposUpdateCmd represents the update command
+var reposUpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Updates cached component repositories",
+	Long: `Downloads remote component repositories again regardless of age of cached files.
+
+Usage: e repos update [name]
+
+By default all repositories are updated. Conditional requests (ETag and Last-Modified) are used so unchanged
+repositories are not downloaded again. Cached repositories are also refreshed automatically when they are older than
+repository-ttl (24h by default) set in config file; if refresh fails, cached repository is used with warning.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("repos update called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 1 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		var sources []repository.Source
+		for _, s := range repository.Sources() {
+			if len(args) == 0 || s.Name == args[0] {
+				sources = append(sources, s)
+			}
+		}
+		if len(sources) == 0 {
+			errUpdateRepository(errors.New(fmt.Sprintf("unknown repository %s", args[0])))
+		}
+		var failed []string
+		for _, s := range sources {
+			if !s.IsRemote() {
+				fmt.Printf("Repository %s is local file, skipped\n", s.Name)
+				continue
+			}
+			modified, err := s.Update()
+			if err != nil {
+				warnUpdateRepository(err, s.Name)
+				failed = append(failed, s.Name)
+				continue
+			}
+			if modified {
+				fmt.Printf("Updated repository %s\n", s.Name)
+			} else {
+				fmt.Printf("Repository %s is up to date\n", s.Name)
+			}
+		}
+		if len(failed) > 0 {
+			errUpdateRepository(errors.New(fmt.Sprintf("cannot update repositories: %s", strings.Join(failed, ", "))))
+		}
+	},
+}
+
+// reposLintCmd represents the lint command
+var reposLintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Validates component repository file",
+	Long: `Validates component repository file before it's published.
+
+Usage: e repos lint <file>
+
+File has to be correct v1 repository: unknown fields are not allowed, component names have to be unique, each
+component has to have known type and exactly one version marked as latest, versions have to be unique semantic
+versions with image, and commands have to have names. All found problems are listed. The same validation is applied
+to downloaded repositories before they are cached.`,
+	PreRun: func(cmd *cobra.Command, args []string) {
+		debug("repos lint called")
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) != 1 {
+			errIncorrectNumberOfArguments(errors.New(fmt.Sprintf("found %d args", len(args))))
+		}
+		data, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			errLintRepository(err)
+		}
+		err = repository.Lint(data)
+		if ve, ok := err.(*repository.ValidationError); ok {
+			for _, p := range ve.Problems {
+				fmt.Printf("%s: %s\n", args[0], p)
+			}
+			errLintRepository(errors.New(fmt.Sprintf("found %d problems in %s", len(ve.Problems), args[0])))
+		}
+		if err != nil {
+			errLintRepository(err)
+		}
+		fmt.Printf("Repository file %s is valid\n", args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reposCmd)
+	reposCmd.AddCommand(reposAddCmd)
+	reposCmd.AddCommand(reposRemoveCmd)
+	reposCmd.AddCommand(reposListCmd)
+	reposCmd.AddCommand(reposUpdateCmd)
+	reposCmd.AddCommand(reposLintCmd)
+
+	reposAddCmd.Flags().IntVar(&reposPriority, "priority", 0, "priority of repository (components from repositories with higher priority take precedence)")
+}
@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/epiphany-platform/cli/pkg/configuration"
+	_ "github.com/epiphany-platform/cli/pkg/docker" // registers docker and podman runtimes
+	"github.com/epiphany-platform/cli/pkg/output"
 	"github.com/epiphany-platform/cli/pkg/util"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
@@ -11,8 +14,10 @@ import (
 )
 
 var (
-	cfgDir   string
-	logLevel string
+	cfgDir               string
+	logLevel             string
+	environmentReference string
+	outputFormat         string
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -41,6 +46,8 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgDir, "configDir", "", fmt.Sprintf("config directory (default is %s)", util.DefaultConfigurationDirectory))
+	rootCmd.PersistentFlags().StringVarP(&environmentReference, "environment", "E", "", "environment (name, UUID or unique UUID prefix) to use instead of current one")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", output.FormatText, "output format of informational commands (values: [text, json, yaml, template=<go template>])")
 	rootCmd.PersistentFlags().StringVar(&logLevel, "logLevel", "", fmt.Sprintf("log level (default is warn, values: [debug, info, error, fatal])"))
 
 	// Cobra also supports local flags, which will only run
@@ -63,6 +70,10 @@ func initConfig() {
 		zerolog.SetGlobalLevel(zerolog.WarnLevel)
 	}
 
+	if err := output.Validate(outputFormat); err != nil {
+		errIncorrectOutputFormat(err)
+	}
+
 	debug("initializing root config")
 	if cfgDir != "" {
 		config, err := configuration.SetConfigDirectory(cfgDir)
@@ -87,3 +98,11 @@ func initConfig() {
 		infoConfigFile(viper.ConfigFileUsed())
 	}
 }
+
+// printOutput prints v in format selected with global --output flag. Text format prints text.
+func printOutput(v interface{}, text string) {
+	err := output.Write(os.Stdout, outputFormat, v, text)
+	if err != nil {
+		errPrintOutput(err)
+	}
+}
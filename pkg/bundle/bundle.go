@@ -0,0 +1,165 @@
+package bundle
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/epiphany-platform/cli/pkg/repository"
+	"github.com/epiphany-platform/cli/pkg/runtime"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	ComponentFileName = "component.yaml"
+	ImageFileName     = "image.tar"
+)
+
+//Export writes bundle of component to w. Bundle is tar archive containing ComponentFileName with repository
+//definition of component (with exactly one version) and ImageFileName with image of that version saved using rt.
+//Image has to be present locally. Repository component comes from is not stored in bundle.
+func Export(rt runtime.Runtime, c *repository.Component, w io.Writer) error {
+	if len(c.Versions) != 1 {
+		return errors.New(fmt.Sprintf("expected exactly one version of component %s but found %d", c.Name, len(c.Versions)))
+	}
+	exported := *c
+	exported.Repository = ""
+	definition, err := yaml.Marshal(exported)
+	if err != nil {
+		return err
+	}
+
+	image, err := ioutil.TempFile("", "e-bundle-image-*.tar")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(image.Name())
+	defer image.Close()
+	debug("will try to save image %s to %s", c.Versions[0].Image, image.Name())
+	err = rt.Save(c.Versions[0].Image, image)
+	if err != nil {
+		return err
+	}
+	size, err := image.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	_, err = image.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(w)
+	now := time.Now()
+	err = tw.WriteHeader(&tar.Header{
+		Name:    ComponentFileName,
+		Mode:    0644,
+		Size:    int64(len(definition)),
+		ModTime: now,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(definition)
+	if err != nil {
+		return err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Name:    ImageFileName,
+		Mode:    0644,
+		Size:    size,
+		ModTime: now,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, image)
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+//Import reads bundle created by Export from r, loads image from it using rt (rendering load progress to progress
+//writer) and adds component definition to imported repository. Component definition is validated before image is
+//loaded and image of component version has to be present after loading. It returns imported component.
+func Import(rt runtime.Runtime, r io.Reader, progress io.Writer) (*repository.Component, error) {
+	tr := tar.NewReader(r)
+	var c *repository.Component
+	imageLoaded := false
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch header.Name {
+		case ComponentFileName:
+			data, err := ioutil.ReadAll(tr)
+			if err != nil {
+				return nil, err
+			}
+			c = &repository.Component{}
+			err = yaml.Unmarshal(data, c)
+			if err != nil {
+				return nil, err
+			}
+			err = validate(*c)
+			if err != nil {
+				return nil, err
+			}
+		case ImageFileName:
+			if c == nil {
+				return nil, errors.New(fmt.Sprintf("%s has to precede %s in bundle", ComponentFileName, ImageFileName))
+			}
+			debug("will try to load image %s", c.Versions[0].Image)
+			err = rt.Load(tr, progress)
+			if err != nil {
+				return nil, err
+			}
+			imageLoaded = true
+		default:
+			debug("skipping unknown bundle entry %s", header.Name)
+		}
+	}
+	if c == nil {
+		return nil, errors.New(fmt.Sprintf("missing %s in bundle", ComponentFileName))
+	}
+	if !imageLoaded {
+		return nil, errors.New(fmt.Sprintf("missing %s in bundle", ImageFileName))
+	}
+	_, err := rt.Inspect(c.Versions[0].Image)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("image %s of component %s not found after loading bundle: %v", c.Versions[0].Image, c.Name, err))
+	}
+	err = repository.AddImported(*c)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+//validate checks component definition taken from bundle the same way as components of downloaded repositories.
+//Bundle has to contain exactly one version and cannot claim to come from repository.
+func validate(c repository.Component) error {
+	if len(c.Versions) != 1 {
+		return errors.New(fmt.Sprintf("expected exactly one version of component %s in bundle but found %d", c.Name, len(c.Versions)))
+	}
+	if c.Repository != "" {
+		return errors.New(fmt.Sprintf("unexpected repository %s of component %s in bundle", c.Repository, c.Name))
+	}
+	//exported version doesn't have to be the latest one in repository, imported one is marked latest by AddImported
+	c.Versions = []repository.ComponentVersion{c.Versions[0]}
+	c.Versions[0].IsLatest = true
+	return repository.V1{
+		Version:    "v1",
+		Kind:       "Repository",
+		Components: []repository.Component{c},
+	}.Validate()
+}
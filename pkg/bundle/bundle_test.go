@@ -0,0 +1,213 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/epiphany-platform/cli/pkg/repository"
+	"github.com/epiphany-platform/cli/pkg/runtime"
+	"github.com/epiphany-platform/cli/pkg/util"
+	"github.com/rs/zerolog"
+)
+
+func setup(t *testing.T) string {
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	dir, err := ioutil.TempDir("", "*-e-bundle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	util.UsedConfigurationDirectory = dir
+	util.UsedRepositoryFile = path.Join(dir, util.DefaultV1RepositoryFileName)
+	err = ioutil.WriteFile(util.UsedRepositoryFile, []byte(`version: v1
+kind: Repository
+components:
+- name: c2
+  type: docker
+  versions:
+  - version: 0.2.0
+    latest: true
+    image: registry.io/c2:0.2.0
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestExportImport(t *testing.T) {
+	dir := setup(t)
+	defer os.RemoveAll(dir)
+
+	c := &repository.Component{
+		Name: "c1",
+		Type: "docker",
+		Versions: []repository.ComponentVersion{
+			{
+				Version:  "0.1.0",
+				IsLatest: true,
+				Image:    "registry.io/c1:0.1.0",
+				Mounts:   []string{},
+				Commands: []repository.ComponentCommand{
+					{Name: "init", Command: "init", Envs: map[string]string{}, Args: []string{}},
+				},
+			},
+		},
+	}
+
+	source := runtime.NewFake()
+	err := Export(source, c, &bytes.Buffer{})
+	if err == nil {
+		t.Errorf("expected error on export of not present image")
+	}
+	_, _ = source.Pull("registry.io/c1:0.1.0", nil, nil)
+	var b bytes.Buffer
+	err = Export(source, c, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	tr := tar.NewReader(bytes.NewReader(b.Bytes()))
+	for h, err := tr.Next(); err == nil; h, err = tr.Next() {
+		names = append(names, h.Name)
+	}
+	if !reflect.DeepEqual(names, []string{ComponentFileName, ImageFileName}) {
+		t.Errorf("got bundle entries %#v", names)
+	}
+
+	target := runtime.NewFake()
+	got, err := Import(target, &b, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Errorf("got = %#v, want %#v", got, c)
+	}
+	if _, err := target.Inspect("registry.io/c1:0.1.0"); err != nil {
+		t.Errorf("expected image to be loaded: %v", err)
+	}
+	repo := repository.GetRepository()
+	if _, err := repo.GetComponentByName("c2"); err != nil {
+		t.Errorf("expected repository component to be kept: %v", err)
+	}
+	imported, err := repo.GetComponentByName("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := *c
+	want.Versions = []repository.ComponentVersion{c.Versions[0]}
+	want.Versions[0].Imported = true
+	if !reflect.DeepEqual(imported, &want) {
+		t.Errorf("got imported = %#v, want %#v", imported, &want)
+	}
+
+	//repository component comes from is not exported
+	c.Repository = "r"
+	b.Reset()
+	err = Export(source, c, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Import(target, &b, nil); err != nil {
+		t.Errorf("got error of import of component exported from repository %v", err)
+	}
+}
+
+func TestImport_Incorrect(t *testing.T) {
+	dir := setup(t)
+	defer os.RemoveAll(dir)
+
+	var b bytes.Buffer
+	tw := tar.NewWriter(&b)
+	_ = tw.WriteHeader(&tar.Header{Name: "other", Mode: 0644, Size: 1})
+	_, _ = tw.Write([]byte("x"))
+	_ = tw.Close()
+	_, err := Import(runtime.NewFake(), &b, nil)
+	if err == nil || err.Error() != "missing component.yaml in bundle" {
+		t.Errorf("got error %v", err)
+	}
+}
+
+func TestImport_incorrectComponent(t *testing.T) {
+	dir := setup(t)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		name       string
+		definition string
+		image      string
+		wantErr    string
+	}{
+		{
+			name: "empty image",
+			definition: `name: c1
+type: docker
+versions:
+- version: 0.1.0
+  image: ""`,
+			image:   "registry.io/c1:0.1.0",
+			wantErr: "invalid repository: component c1 version 0.1.0 has empty image",
+		},
+		{
+			name: "unknown type",
+			definition: `name: c1
+type: other
+versions:
+- version: 0.1.0
+  image: registry.io/c1:0.1.0`,
+			image:   "registry.io/c1:0.1.0",
+			wantErr: "invalid repository: component c1 has unknown type \"other\" (expected one of docker)",
+		},
+		{
+			name: "repository set",
+			definition: `name: c1
+type: docker
+repository: trusted
+versions:
+- version: 0.1.0
+  image: registry.io/c1:0.1.0`,
+			image:   "registry.io/c1:0.1.0",
+			wantErr: "unexpected repository trusted of component c1 in bundle",
+		},
+		{
+			name: "different image",
+			definition: `name: c1
+type: docker
+versions:
+- version: 0.1.0
+  image: registry.io/c1:0.1.0`,
+			image:   "registry.io/other:0.1.0",
+			wantErr: "image registry.io/c1:0.1.0 of component c1 not found after loading bundle: no such image: registry.io/c1:0.1.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := runtime.NewFake()
+			_, _ = source.Pull(tt.image, nil, nil)
+			var image bytes.Buffer
+			if err := source.Save(tt.image, &image); err != nil {
+				t.Fatal(err)
+			}
+			var b bytes.Buffer
+			tw := tar.NewWriter(&b)
+			_ = tw.WriteHeader(&tar.Header{Name: ComponentFileName, Mode: 0644, Size: int64(len(tt.definition))})
+			_, _ = tw.Write([]byte(tt.definition))
+			_ = tw.WriteHeader(&tar.Header{Name: ImageFileName, Mode: 0644, Size: int64(image.Len())})
+			_, _ = tw.Write(image.Bytes())
+			_ = tw.Close()
+
+			_, err := Import(runtime.NewFake(), &b, nil)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("got error %v, want %s", err, tt.wantErr)
+			}
+			if _, err := repository.GetRepository().GetComponentByName("c1"); err == nil {
+				t.Errorf("expected component not to be imported")
+			}
+		})
+	}
+}
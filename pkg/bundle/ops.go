@@ -0,0 +1,22 @@
+package bundle
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	logger zerolog.Logger
+)
+
+func init() {
+	logger = log.With().
+		Str("package", "bundle").
+		Logger()
+}
+
+func debug(format string, v ...interface{}) {
+	logger.
+		Debug().
+		Msgf(format, v...)
+}
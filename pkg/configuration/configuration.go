@@ -1,13 +1,18 @@
 package configuration
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"time"
 
 	"github.com/epiphany-platform/cli/pkg/environment"
+	"github.com/epiphany-platform/cli/pkg/repository"
+	"github.com/epiphany-platform/cli/pkg/schema"
+	"github.com/epiphany-platform/cli/pkg/storage"
 	"github.com/epiphany-platform/cli/pkg/util"
 	"github.com/google/uuid"
 	"gopkg.in/yaml.v2"
@@ -19,10 +24,26 @@ const (
 	KindConfig Kind = "Config"
 )
 
+var (
+	//configSchema lists migrations of Config. Versions before v1 had no version and kind fields.
+	configSchema = &schema.Schema{
+		Kind:    string(KindConfig),
+		Current: "v1",
+		Migrations: []schema.Migration{
+			{From: schema.LegacyVersion, To: "v1"},
+		},
+	}
+)
+
 type Config struct {
-	Version            string    `yaml:"version"`
-	Kind               Kind      `yaml:"kind"`
-	CurrentEnvironment uuid.UUID `yaml:"current-environment"`
+	Version            string                  `yaml:"version"`
+	Kind               Kind                    `yaml:"kind"`
+	CurrentEnvironment uuid.UUID               `yaml:"current-environment"`
+	Runtime            string                  `yaml:"runtime,omitempty"`
+	Storage            *storage.Config         `yaml:"storage,omitempty"`
+	Repositories       []repository.Source     `yaml:"repositories,omitempty"`
+	RepositoryTTL      string                  `yaml:"repository-ttl,omitempty"`
+	TrustedKeys        []repository.TrustedKey `yaml:"trusted-keys,omitempty"`
 }
 
 //TODO return newly created environment uuid
@@ -38,6 +59,83 @@ func (c *Config) CreateNewEnvironment(name string) error {
 	return c.Save()
 }
 
+//DeleteEnvironment removes environment and clears CurrentEnvironment if it pointed to removed environment
+func (c *Config) DeleteEnvironment(e *environment.Environment) error {
+	debug("will try to delete environment %s", e.Uuid.String())
+	err := e.Delete()
+	if err != nil {
+		return err
+	}
+	if c.CurrentEnvironment != e.Uuid {
+		return nil
+	}
+	c.CurrentEnvironment = uuid.Nil
+	debug("will try to save updated config %+v", c)
+	return c.Save()
+}
+
+//AddRepository adds component repository source. If there were no sources configured so far, default repository
+//is added as well, so it's still used next to added one.
+func (c *Config) AddRepository(s repository.Source) error {
+	debug("will try to add repository %s (%s)", s.Name, s.Url)
+	repositories := c.Repositories
+	if len(repositories) == 0 {
+		repositories = []repository.Source{repository.DefaultSource()}
+	}
+	err := repository.ValidateSource(s, repositories)
+	if err != nil {
+		return err
+	}
+	c.Repositories = append(repositories, s)
+	repository.UseSources(c.Repositories)
+	debug("will try to save updated config %+v", c)
+	return c.Save()
+}
+
+//RemoveRepository removes component repository source and its cache file. The last repository cannot be removed.
+func (c *Config) RemoveRepository(name string) error {
+	debug("will try to remove repository %s", name)
+	var removed *repository.Source
+	var repositories []repository.Source
+	for _, s := range repository.Sources() {
+		if s.Name == name {
+			s := s
+			removed = &s
+		}
+	}
+	for _, s := range c.Repositories {
+		if s.Name != name {
+			repositories = append(repositories, s)
+		}
+	}
+	if removed == nil {
+		return errors.New(fmt.Sprintf("unknown repository %s", name))
+	}
+	if len(repositories) == 0 {
+		return errors.New(fmt.Sprintf("cannot remove the last repository %s", name))
+	}
+	c.Repositories = repositories
+	repository.UseSources(c.Repositories)
+	err := removed.RemoveCache()
+	if err != nil {
+		return err
+	}
+	debug("will try to save updated config %+v", c)
+	return c.Save()
+}
+
+//repositoryTTL parses RepositoryTTL. If it's not set repository.DefaultCacheTTL is returned.
+func (c *Config) repositoryTTL() (time.Duration, error) {
+	if c.RepositoryTTL == "" {
+		return repository.DefaultCacheTTL, nil
+	}
+	ttl, err := time.ParseDuration(c.RepositoryTTL)
+	if err != nil {
+		return 0, errors.New(fmt.Sprintf("incorrect repository-ttl %s: %v", c.RepositoryTTL, err))
+	}
+	return ttl, nil
+}
+
 //SetUsedEnvironment to another value (NOTE: there is no additional error check)
 func (c *Config) SetUsedEnvironment(u uuid.UUID) error {
 	debug("changing used environment to %s", u.String())
@@ -136,7 +234,7 @@ func makeOrGetConfig() (*Config, error) {
 	if _, err := os.Stat(util.UsedConfigFile); os.IsNotExist(err) {
 		debug("there is no config file, will try to initialize one")
 		config := &Config{
-			Version: "v1",
+			Version: configSchema.Current,
 			Kind:    KindConfig,
 		}
 		err = config.Save()
@@ -147,16 +245,43 @@ func makeOrGetConfig() (*Config, error) {
 	}
 	debug("will try to load existing config file from %s", util.UsedConfigFile)
 	config := &Config{}
-	debug("trying to open %s file", util.UsedConfigFile)
-	file, err := os.Open(util.UsedConfigFile)
+	debug("trying to read %s file", util.UsedConfigFile)
+	data, err := ioutil.ReadFile(util.UsedConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	migrated, version, err := configSchema.Upgrade(data)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
-	d := yaml.NewDecoder(file)
+	d := yaml.NewDecoder(bytes.NewReader(migrated))
 	debug("will try to decode file %s to yaml", util.UsedConfigFile)
 	if err := d.Decode(&config); err != nil {
 		return nil, err
 	}
+	if version != configSchema.Current {
+		backupFile := fmt.Sprintf("%s.%s.bak", util.UsedConfigFile, version)
+		infoMigrated(util.UsedConfigFile, version, configSchema.Current, backupFile)
+		err = ioutil.WriteFile(backupFile, data, 0644)
+		if err != nil {
+			return nil, err
+		}
+		err = config.Save()
+		if err != nil {
+			return nil, err
+		}
+	}
+	util.UsedRuntime = config.Runtime
+	environment.UseStorage(config.Storage)
+	repository.UseSources(config.Repositories)
+	ttl, err := config.repositoryTTL()
+	if err != nil {
+		return nil, err
+	}
+	repository.UseCacheTTL(ttl)
+	err = repository.UseTrustedKeys(config.TrustedKeys)
+	if err != nil {
+		return nil, err
+	}
 	return config, nil
 }
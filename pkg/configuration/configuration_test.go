@@ -9,7 +9,11 @@ import (
 	"path"
 	"reflect"
 	"testing"
+	"time"
 
+	"github.com/epiphany-platform/cli/pkg/environment"
+	"github.com/epiphany-platform/cli/pkg/repository"
+	"github.com/epiphany-platform/cli/pkg/storage"
 	"github.com/epiphany-platform/cli/pkg/util"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
@@ -197,6 +201,59 @@ current-environment: b3d7be89-461e-41eb-b130-0b4db1555d85`),
 	}
 }
 
+func TestConfig_DeleteEnvironment(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory = setup(t, "delete")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+	_ = ioutil.WriteFile(util.UsedConfigFile, []byte(`version: v1
+kind: Config
+current-environment: 00000000-0000-0000-0000-000000000000`), 0644)
+	defer ioutil.WriteFile(util.UsedConfigFile, []byte(""), 0664)
+
+	c, err := GetConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.CreateNewEnvironment("e1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e1, err := environment.Get(c.CurrentEnvironment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.CreateNewEnvironment("e2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e2, err := environment.Get(c.CurrentEnvironment)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = c.DeleteEnvironment(e1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.CurrentEnvironment != e2.Uuid {
+		t.Errorf("got current environment %s, want %s", c.CurrentEnvironment, e2.Uuid)
+	}
+	if _, err := os.Stat(path.Join(util.UsedEnvironmentDirectory, e1.Uuid.String())); !os.IsNotExist(err) {
+		t.Errorf("expected environment directory to be removed but got: %v", err)
+	}
+
+	err = c.DeleteEnvironment(e2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := GetConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CurrentEnvironment != uuid.Nil {
+		t.Errorf("got current environment %s, want nil UUID", got.CurrentEnvironment)
+	}
+}
+
 func TestConfig_Save(t *testing.T) {
 	util.UsedConfigFile, util.UsedConfigurationDirectory = setup(t, "save")
 	defer os.RemoveAll(util.UsedConfigurationDirectory)
@@ -396,6 +453,69 @@ current-environment: 00000000-0000-0000-0000-000000000000`),
 			},
 			wantErr: nil,
 		},
+		{
+			name:       "without version",
+			configPath: tempFile,
+			mocked:     []byte(`current-environment: 3e5b7269-1b3d-4003-9454-9f472857633a`),
+			want: &Config{
+				Version:            "v1",
+				Kind:               KindConfig,
+				CurrentEnvironment: uuid.MustParse("3e5b7269-1b3d-4003-9454-9f472857633a"),
+			},
+			wantErr: nil,
+		},
+		{
+			name:       "kebab-case keys",
+			configPath: tempFile,
+			mocked: []byte(`version: v1
+kind: Config
+current-environment: 3e5b7269-1b3d-4003-9454-9f472857633a
+repository-ttl: 12h
+storage:
+  type: s3
+  s3:
+    endpoint: https://minio.example.com:9000
+    bucket: epiphany
+    access-key: ak
+    secret-key: sk
+    session-token: st`),
+			want: &Config{
+				Version:            "v1",
+				Kind:               KindConfig,
+				CurrentEnvironment: uuid.MustParse("3e5b7269-1b3d-4003-9454-9f472857633a"),
+				RepositoryTTL:      "12h",
+				Storage: &storage.Config{
+					Type: storage.TypeS3,
+					S3: &storage.S3Config{
+						Endpoint:     "https://minio.example.com:9000",
+						Bucket:       "epiphany",
+						AccessKey:    "ak",
+						SecretKey:    "sk",
+						SessionToken: "st",
+					},
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name:       "incorrect trusted key",
+			configPath: tempFile,
+			mocked: []byte(`version: v1
+kind: Config
+current-environment: 3e5b7269-1b3d-4003-9454-9f472857633a
+trusted-keys:
+- name: k1
+  public-key: c2hvcnQ=`),
+			wantErr: errors.New("incorrect trusted key k1 (expected base64 encoded 32 bytes ed25519 public key)"),
+		},
+		{
+			name:       "newer version",
+			configPath: tempFile,
+			mocked: []byte(`version: v2
+kind: Config
+current-environment: 3e5b7269-1b3d-4003-9454-9f472857633a`),
+			wantErr: errors.New("Config file has schema version v2 but this version of e supports only up to v1, please upgrade e"),
+		},
 		{
 			name:       "not existing",
 			configPath: path.Join(tempDirectory, "non-existing-config-directory"),
@@ -449,3 +569,95 @@ func assertPanic(t *testing.T, f func()) {
 	}()
 	f()
 }
+
+func TestConfig_AddRemoveRepository(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory = setup(t, "repositories")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+	util.UsedRepositoryFile = path.Join(util.UsedConfigurationDirectory, util.DefaultV1RepositoryFileName)
+	defer func() { util.UsedRepositoryFile = "" }()
+	defer repository.UseSources(nil)
+
+	c := &Config{Version: "v1", Kind: KindConfig}
+	err := c.RemoveRepository(repository.DefaultSourceName)
+	if isWrongResult(t, err, errors.New("cannot remove the last repository default")) {
+		return
+	}
+	internal := repository.Source{Name: "internal", Url: "https://example.com/v1.yaml", Priority: 10}
+	err = c.AddRepository(internal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []repository.Source{repository.DefaultSource(), internal}
+	if !reflect.DeepEqual(c.Repositories, want) {
+		t.Errorf("got repositories %#v, want %#v", c.Repositories, want)
+	}
+	err = c.AddRepository(internal)
+	if isWrongResult(t, err, errors.New("repository internal already exists")) {
+		return
+	}
+
+	_ = ioutil.WriteFile(util.UsedRepositoryFile, []byte("cache"), 0644)
+	err = c.RemoveRepository(repository.DefaultSourceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(util.UsedRepositoryFile); !os.IsNotExist(err) {
+		t.Errorf("expected cache of removed repository to be removed but got: %v", err)
+	}
+	err = c.RemoveRepository("unknown")
+	if isWrongResult(t, err, errors.New("unknown repository unknown")) {
+		return
+	}
+
+	got, err := makeOrGetConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Repositories, []repository.Source{internal}) {
+		t.Errorf("got saved repositories %#v", got.Repositories)
+	}
+	if !reflect.DeepEqual(repository.Sources(), []repository.Source{internal}) {
+		t.Errorf("got used sources %#v", repository.Sources())
+	}
+}
+
+func TestConfig_repositoryTTL(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     string
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			name: "default",
+			want: repository.DefaultCacheTTL,
+		},
+		{
+			name: "configured",
+			ttl:  "30m",
+			want: 30 * time.Minute,
+		},
+		{
+			name: "disabled",
+			ttl:  "0",
+			want: 0,
+		},
+		{
+			name:    "incorrect",
+			ttl:     "day",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{RepositoryTTL: tt.ttl}
+			got, err := c.repositoryTTL()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got ttl %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
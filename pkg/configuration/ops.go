@@ -42,3 +42,9 @@ func errSave(err error) {
 		Err(err).
 		Msg("failed to save")
 }
+
+func infoMigrated(file string, from string, to string, backup string) {
+	logger.
+		Info().
+		Msgf("migrated %s from schema version %s to %s (backup saved as %s)", file, from, to, backup)
+}
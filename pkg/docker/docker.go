@@ -1,13 +1,18 @@
 package docker
 
 import (
-	"bufio"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"os/signal"
 	"path"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/pkg/stdcopy"
@@ -15,80 +20,154 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
+	"github.com/epiphany-platform/cli/pkg/runtime"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
-type Image struct {
-	Name string
+//Runtime is runtime.Runtime implementation talking to Docker Engine API. It's used for Podman as well, as Podman
+//exposes Docker compatible API on its socket.
+type Runtime struct {
+	host string
 }
 
-func (i *Image) Pull() (string, error) { //TODO remove splitting log streams here, but use zerolog multiwriter
+func init() {
+	runtime.Register("docker", func() (runtime.Runtime, error) {
+		return New(""), nil
+	})
+	runtime.Register("podman", func() (runtime.Runtime, error) {
+		return NewPodman(), nil
+	})
+}
+
+//New creates Runtime connecting to provided host. If host is empty DOCKER_HOST and related variables are used.
+func New(host string) *Runtime {
+	return &Runtime{host: host}
+}
+
+//NewPodman creates Runtime connecting to Podman API socket. Socket is taken from CONTAINER_HOST variable or default
+//rootful or rootless socket location is used.
+func NewPodman() *Runtime {
+	host := os.Getenv("CONTAINER_HOST")
+	if host == "" {
+		if os.Geteuid() == 0 {
+			host = "unix:///run/podman/podman.sock"
+		} else {
+			runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+			if runtimeDir == "" {
+				runtimeDir = fmt.Sprintf("/run/user/%d", os.Getuid())
+			}
+			host = "unix://" + path.Join(runtimeDir, "podman", "podman.sock")
+		}
+	}
+	debug("will use podman socket %s", host)
+	return New(host)
+}
+
+func (r *Runtime) Pull(image string, auth *runtime.Auth, progress io.Writer) (string, error) {
 	debug("will try to pull")
-	ctx, cli, err := clientAndContext()
+	ctx, cli, err := r.clientAndContext()
 	if err != nil {
 		return "", err
 	}
-	reader, err := cli.ImagePull(ctx, i.Name, types.ImagePullOptions{}) //TODO format output
-	logR, logW := io.Pipe()
-	stdoutR, stdoutW := io.Pipe()
-
-	done := make(chan bool)
-	defer close(done)
-
-	var result string
-	go func() {
-		s := bufio.NewScanner(stdoutR)
-		for s.Scan() {
-			txt := s.Text()
-			debugJson([]byte(txt), "pulling")
+	options := types.ImagePullOptions{}
+	if auth != nil {
+		options.RegistryAuth, err = encodeAuth(auth)
+		if err != nil {
+			return "", err
 		}
-		done <- true
-	}()
-
-	go func() {
-		buf := new(strings.Builder)
-		_, _ = io.Copy(buf, logR)
-		result = buf.String()
-		done <- true
-	}()
-
-	go func() {
-		defer logW.Close()
-		defer stdoutW.Close()
-
-		// build the MultiWriter for all the pipes
-		mw := io.MultiWriter(logW, stdoutW)
+	}
+	reader, err := cli.ImagePull(ctx, image, options)
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	logs := new(strings.Builder)
+	err = displayProgress(io.TeeReader(reader, logs), progress)
+	return logs.String(), err
+}
 
-		// copy the data into the MultiWriter
-		_, _ = io.Copy(mw, reader)
-	}()
+//encodeAuth encodes credentials to format expected in X-Registry-Auth header
+func encodeAuth(auth *runtime.Auth) (string, error) {
+	data, err := json.Marshal(types.AuthConfig{
+		Username:      auth.Username,
+		Password:      auth.Password,
+		ServerAddress: auth.ServerAddress,
+		IdentityToken: auth.IdentityToken,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(data), nil
+}
 
-	for c := 0; c < 2; c++ {
-		<-done
+func (r *Runtime) Save(image string, w io.Writer) error {
+	debug("will try to save image %s", image)
+	ctx, cli, err := r.clientAndContext()
+	if err != nil {
+		return err
 	}
+	reader, err := cli.ImageSave(ctx, []string{image})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	_, err = io.Copy(w, reader)
+	return err
+}
 
-	reader.Close()
+func (r *Runtime) Load(reader io.Reader, progress io.Writer) error {
+	debug("will try to load image")
+	ctx, cli, err := r.clientAndContext()
 	if err != nil {
-		return result, err
+		return err
+	}
+	response, err := cli.ImageLoad(ctx, reader, false)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if !response.JSON {
+		if progress == nil {
+			progress = ioutil.Discard
+		}
+		_, err = io.Copy(progress, response.Body)
+		return err
 	}
-	return result, nil
+	return displayProgress(response.Body, progress)
 }
 
-type Job struct {
-	Image                string
-	Command              string
-	Args                 []string
-	WorkDirectory        string
-	Mounts               []string
-	MountPath            string
-	EnvironmentVariables map[string]string
+func (r *Runtime) Remove(image string) error {
+	debug("will try to remove image %s", image)
+	ctx, cli, err := r.clientAndContext()
+	if err != nil {
+		return err
+	}
+	_, err = cli.ImageRemove(ctx, image, types.ImageRemoveOptions{PruneChildren: true})
+	return err
 }
 
-func (j Job) Run() error {
-	return run(j)
+func (r *Runtime) Inspect(image string) (*runtime.ImageInfo, error) {
+	debug("will try to inspect image %s", image)
+	ctx, cli, err := r.clientAndContext()
+	if err != nil {
+		return nil, err
+	}
+	inspect, _, err := cli.ImageInspectWithRaw(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	return &runtime.ImageInfo{
+		ID:          inspect.ID,
+		RepoTags:    inspect.RepoTags,
+		RepoDigests: inspect.RepoDigests,
+	}, nil
 }
 
-func run(job Job) error {
-	ctx, cli, err := clientAndContext()
+//Run creates and starts container for job and stops it (with StopGracePeriod) when provided context is cancelled.
+//Context is used only to control container lifetime, Docker API calls use background context so that container can
+//be always stopped, inspected and removed.
+func (r *Runtime) Run(runCtx context.Context, job runtime.Job) error {
+	ctx, cli, err := r.clientAndContext()
 	if err != nil {
 		return err
 	}
@@ -111,11 +190,16 @@ func run(job Job) error {
 	resp, err := cli.ContainerCreate(
 		ctx,
 		&container.Config{
-			Image:      job.Image,
-			Cmd:        commandAndArgs,
-			WorkingDir: job.WorkDirectory,
-			Env:        envs,
-			Tty:        false,
+			Image:        job.Image,
+			Cmd:          commandAndArgs,
+			WorkingDir:   job.WorkDirectory,
+			Env:          envs,
+			Tty:          job.Interactive,
+			OpenStdin:    job.Interactive,
+			StdinOnce:    job.Interactive,
+			AttachStdin:  job.Interactive,
+			AttachStdout: job.Interactive,
+			AttachStderr: job.Interactive,
 		}, &container.HostConfig{
 			Mounts: mounts,
 		},
@@ -127,30 +211,212 @@ func run(job Job) error {
 	}
 	defer removeFinishedContainer(cli, ctx, resp.ID)
 
-	if err := cli.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+	stopForwarding := forwardSignals(ctx, cli, resp.ID, job.StopGracePeriod)
+	defer stopForwarding()
+
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(runCtx, job.Timeout)
+		defer cancel()
+	}
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-runCtx.Done():
+			warnStoppingContainer(runCtx.Err())
+			stopContainer(ctx, cli, resp.ID, job.StopGracePeriod)
+		case <-finished:
+		}
+	}()
+
+	stdout, stderr := job.Stdout, job.Stderr
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stderr == nil {
+		stderr = os.Stderr
+	}
+	if job.Interactive {
+		err = runInteractive(ctx, cli, resp.ID, stdout)
+	} else {
+		err = runDetached(ctx, cli, resp.ID, stdout, stderr)
+	}
+	if err != nil {
 		return err
 	}
-	out, err := cli.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Follow: true})
+
+	debug("will wait for container %s to finish", resp.ID)
+	exitCode, err := cli.ContainerWait(ctx, resp.ID)
 	if err != nil {
 		return err
 	}
+	debug("container %s finished with exit code %d", resp.ID, exitCode)
+	if runCtx.Err() != nil && exitCode == 0 {
+		return runCtx.Err()
+	}
+	if exitCode != 0 {
+		exitErr := &runtime.ExitError{ExitCode: int(exitCode), TimedOut: runCtx.Err() == context.DeadlineExceeded}
+		inspect, err := cli.ContainerInspect(ctx, resp.ID)
+		if err != nil {
+			warnInspectingContainer(err)
+		} else if inspect.State != nil {
+			exitErr.OOMKilled = inspect.State.OOMKilled
+		}
+		return exitErr
+	}
+	return nil
+}
 
-	_, _ = stdcopy.StdCopy(os.Stdout, os.Stderr, out) //TODO write logs to file as well
+//runDetached starts container and follows its logs demultiplexing them into stdout and stderr
+func runDetached(ctx context.Context, cli *client.Client, containerID string, stdout io.Writer, stderr io.Writer) error {
+	if err := cli.ContainerStart(ctx, containerID, types.ContainerStartOptions{}); err != nil {
+		return err
+	}
+	out, err := cli.ContainerLogs(ctx, containerID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Follow: true})
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	_, err = stdcopy.StdCopy(stdout, stderr, out)
+	return err
+}
 
-	return nil
+//runInteractive attaches local stdin and stdout to container with TTY, starts it and copies streams until container
+//output ends. If local stdin is terminal it is switched to raw mode and restored on return. Terminal size changes
+//are forwarded to container.
+func runInteractive(ctx context.Context, cli *client.Client, containerID string, stdout io.Writer) error {
+	hijacked, err := cli.ContainerAttach(ctx, containerID, types.ContainerAttachOptions{
+		Stream: true,
+		Stdin:  true,
+		Stdout: true,
+		Stderr: true,
+	})
+	if err != nil {
+		return err
+	}
+	defer hijacked.Close()
+
+	if err := cli.ContainerStart(ctx, containerID, types.ContainerStartOptions{}); err != nil {
+		return err
+	}
+
+	inFd := int(os.Stdin.Fd())
+	if terminal.IsTerminal(inFd) {
+		state, err := terminal.MakeRaw(inFd)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := terminal.Restore(inFd, state); err != nil {
+				warnRestoringTerminal(err)
+			}
+		}()
+	}
+	outFd := int(os.Stdout.Fd())
+	if terminal.IsTerminal(outFd) {
+		resize := func() {
+			resizeContainer(ctx, cli, containerID, outFd)
+		}
+		resize()
+		stop := notifyResize(resize)
+		defer stop()
+	}
+
+	go func() {
+		_, _ = io.Copy(hijacked.Conn, os.Stdin)
+		if err := hijacked.CloseWrite(); err != nil {
+			debug("closing container stdin failed: %v", err)
+		}
+	}()
+	_, err = io.Copy(stdout, hijacked.Reader)
+	return err
 }
 
-func clientAndContext() (context.Context, *client.Client, error) {
+//resizeContainer sets container TTY size to size of local terminal
+func resizeContainer(ctx context.Context, cli *client.Client, containerID string, fd int) {
+	width, height, err := terminal.GetSize(fd)
+	if err != nil {
+		warnResizingContainer(err)
+		return
+	}
+	err = cli.ContainerResize(ctx, containerID, types.ResizeOptions{Height: uint(height), Width: uint(width)})
+	if err != nil {
+		warnResizingContainer(err)
+	}
+}
+
+func (r *Runtime) clientAndContext() (context.Context, *client.Client, error) {
 	ctx := context.Background()
-	cli, err := client.NewEnvClient()
+	var cli *client.Client
+	var err error
+	if r.host == "" {
+		cli, err = client.NewEnvClient()
+	} else {
+		cli, err = client.NewClient(r.host, client.DefaultVersion, nil, nil)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
 	return ctx, cli, nil
 }
 
+//forwardSignals forwards SIGINT and SIGTERM received by e to container. If container doesn't finish within grace
+//period (or another signal is received) it gets killed. Returned function stops forwarding.
+func forwardSignals(ctx context.Context, cli *client.Client, containerID string, gracePeriod time.Duration) func() {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		var killTimer *time.Timer
+		for {
+			select {
+			case s := <-sigs:
+				if killTimer != nil {
+					killContainer(ctx, cli, containerID, "SIGKILL")
+					continue
+				}
+				name := "SIGTERM"
+				if s == os.Interrupt {
+					name = "SIGINT"
+				}
+				infoForwardingSignal(name, containerID)
+				killContainer(ctx, cli, containerID, name)
+				killTimer = time.AfterFunc(gracePeriod, func() {
+					killContainer(ctx, cli, containerID, "SIGKILL")
+				})
+			case <-done:
+				if killTimer != nil {
+					killTimer.Stop()
+				}
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
+
+func killContainer(ctx context.Context, cli *client.Client, containerID string, signal string) {
+	debug("will send %s to container %s", signal, containerID)
+	err := cli.ContainerKill(ctx, containerID, signal)
+	if err != nil {
+		warnKillingContainer(err)
+	}
+}
+
+//stopContainer sends SIGTERM to container and kills it after grace period
+func stopContainer(ctx context.Context, cli *client.Client, containerID string, gracePeriod time.Duration) {
+	err := cli.ContainerStop(ctx, containerID, &gracePeriod)
+	if err != nil {
+		warnKillingContainer(err)
+	}
+}
+
 func removeFinishedContainer(cli *client.Client, ctx context.Context, containerID string) {
-	err := cli.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{})
+	err := cli.ContainerRemove(ctx, containerID, types.ContainerRemoveOptions{Force: true})
 	if err != nil {
 		warnRemovingContainer(err)
 	}
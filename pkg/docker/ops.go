@@ -34,3 +34,44 @@ func warnRemovingContainer(err error) {
 		Err(err).
 		Msg("cannot remove container after it finished it's job")
 }
+
+func infoForwardingSignal(signal string, containerID string) {
+	logger.
+		Info().
+		Msgf("forwarding %s to container %s", signal, containerID)
+}
+
+func warnStoppingContainer(err error) {
+	logger.
+		Warn().
+		Err(err).
+		Msg("stopping container")
+}
+
+func warnKillingContainer(err error) {
+	logger.
+		Warn().
+		Err(err).
+		Msg("cannot stop container")
+}
+
+func warnInspectingContainer(err error) {
+	logger.
+		Warn().
+		Err(err).
+		Msg("cannot inspect finished container")
+}
+
+func warnRestoringTerminal(err error) {
+	logger.
+		Warn().
+		Err(err).
+		Msg("cannot restore terminal state")
+}
+
+func warnResizingContainer(err error) {
+	logger.
+		Warn().
+		Err(err).
+		Msg("cannot resize container terminal")
+}
@@ -0,0 +1,113 @@
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+//progressMessage is single message of JSON stream returned by pull and load endpoints
+type progressMessage struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	Progress    string `json:"progress"`
+	Stream      string `json:"stream"`
+	Error       string `json:"error"`
+	ErrorDetail *struct {
+		Message string `json:"message"`
+	} `json:"errorDetail"`
+}
+
+//progressRenderer renders progress messages. On terminal every layer (message ID) has its own line updated in
+//place with progress bar. Otherwise only changes of layer status are printed.
+type progressRenderer struct {
+	out      io.Writer
+	terminal bool
+	lines    map[string]int
+	statuses map[string]string
+	count    int
+}
+
+//displayProgress reads JSON stream from in and renders it to out (which can be nil). It returns error if stream
+//contains error message.
+func displayProgress(in io.Reader, out io.Writer) error {
+	if out == nil {
+		out = ioutil.Discard
+	}
+	p := &progressRenderer{
+		out:      out,
+		terminal: isTerminal(out),
+		lines:    make(map[string]int),
+		statuses: make(map[string]string),
+	}
+	d := json.NewDecoder(in)
+	for {
+		var raw json.RawMessage
+		if err := d.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		debugJson(raw, "progress")
+		m := &progressMessage{}
+		if err := json.Unmarshal(raw, m); err != nil {
+			return err
+		}
+		if m.ErrorDetail != nil && m.ErrorDetail.Message != "" {
+			return errors.New(m.ErrorDetail.Message)
+		}
+		if m.Error != "" {
+			return errors.New(m.Error)
+		}
+		p.render(m)
+	}
+}
+
+func (p *progressRenderer) render(m *progressMessage) {
+	if m.Stream != "" {
+		_, _ = fmt.Fprint(p.out, m.Stream)
+		p.count += strings.Count(m.Stream, "\n")
+		return
+	}
+	if m.ID == "" {
+		_, _ = fmt.Fprintln(p.out, m.Status)
+		p.count++
+		return
+	}
+	text := fmt.Sprintf("%s: %s", m.ID, m.Status)
+	if !p.terminal {
+		if p.statuses[m.ID] == m.Status {
+			return
+		}
+		p.statuses[m.ID] = m.Status
+		_, _ = fmt.Fprintln(p.out, text)
+		return
+	}
+	if m.Progress != "" {
+		text = fmt.Sprintf("%s %s", text, m.Progress)
+	}
+	line, ok := p.lines[m.ID]
+	if !ok {
+		p.lines[m.ID] = p.count
+		p.count++
+		_, _ = fmt.Fprintln(p.out, text)
+		return
+	}
+	diff := p.count - line
+	_, _ = fmt.Fprintf(p.out, "\x1b[%dA\r\x1b[2K%s\n", diff, text)
+	if diff > 1 {
+		_, _ = fmt.Fprintf(p.out, "\x1b[%dB", diff-1)
+	}
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && terminal.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,8 @@
+// +build windows
+
+package docker
+
+//notifyResize is no-op on windows as there is no SIGWINCH signal
+func notifyResize(resize func()) func() {
+	return func() {}
+}
@@ -0,0 +1,451 @@
+package environment
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/epiphany-platform/cli/pkg/util"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	archiveManifestFileName = "manifest.yaml"
+	archiveEnvironmentDir   = "environment"
+	kindEnvironmentArchive  = "EnvironmentArchive"
+
+	archiveTypeFile    = "file"
+	archiveTypeDir     = "dir"
+	archiveTypeSymlink = "symlink"
+)
+
+//ErrUUIDCollision is returned by Import when environment with the same UUID as archived one already exists
+var ErrUUIDCollision = errors.New("environment with the same UUID already exists")
+
+//archiveManifest is first entry of environment archive describing all other entries
+type archiveManifest struct {
+	Version     string        `yaml:"version"`
+	Kind        string        `yaml:"kind"`
+	Name        string        `yaml:"name"`
+	Uuid        uuid.UUID     `yaml:"uuid"`
+	IncludeRuns bool          `yaml:"include_runs"`
+	Files       []archiveFile `yaml:"files"`
+}
+
+//archiveFile describes single entry of environment archive
+type archiveFile struct {
+	Path   string      `yaml:"path"`
+	Type   string      `yaml:"type"`
+	Mode   os.FileMode `yaml:"mode"`
+	Size   int64       `yaml:"size,omitempty"`
+	Sha256 string      `yaml:"sha256,omitempty"`
+	Link   string      `yaml:"link,omitempty"`
+}
+
+//Export writes Environment directory (config and mounts of all components, runs only if includeRuns is set) as
+//gzipped tar archive to w. Archive starts with manifest containing sha256 checksums of all files, which is used by
+//Import to verify archive integrity. Mounts kept in shared storage are pulled before they are archived.
+func (e *Environment) Export(w io.Writer, includeRuns bool) error {
+	environmentDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String())
+	manifest := &archiveManifest{
+		Version:     "v1",
+		Kind:        kindEnvironmentArchive,
+		Name:        e.Name,
+		Uuid:        e.Uuid,
+		IncludeRuns: includeRuns,
+	}
+	//config is taken from Environment itself as it might be kept in remote storage
+	config, err := yaml.Marshal(e)
+	if err != nil {
+		return err
+	}
+	manifest.Files = append(manifest.Files, archiveFile{
+		Path:   util.DefaultEnvironmentConfigFileName,
+		Type:   archiveTypeFile,
+		Mode:   0644,
+		Size:   int64(len(config)),
+		Sha256: hashHex(config),
+	})
+	for i := range e.Installed {
+		err = e.Installed[i].pullMounts()
+		if err != nil {
+			return err
+		}
+	}
+	debug("will try to collect files of environment %s", environmentDirectory)
+	err = filepath.Walk(environmentDirectory, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(environmentDirectory, p)
+		if err != nil {
+			return err
+		}
+		if rel == "." || rel == util.DefaultEnvironmentConfigFileName || rel == util.DefaultEnvironmentLockFileName {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if !includeRuns && info.IsDir() && isRunsDirectory(rel) {
+			debug("skipping runs directory %s", rel)
+			return filepath.SkipDir
+		}
+		f := archiveFile{
+			Path: rel,
+			Mode: info.Mode().Perm(),
+		}
+		switch {
+		case info.IsDir():
+			f.Type = archiveTypeDir
+		case info.Mode()&os.ModeSymlink != 0:
+			f.Type = archiveTypeSymlink
+			f.Link, err = os.Readlink(p)
+			if err != nil {
+				return err
+			}
+		case info.Mode().IsRegular():
+			f.Type = archiveTypeFile
+			f.Size = info.Size()
+			f.Sha256, err = fileChecksum(p)
+			if err != nil {
+				return err
+			}
+		default:
+			debug("skipping not regular file %s", rel)
+			return nil
+		}
+		manifest.Files = append(manifest.Files, f)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	data, err := yaml.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Name: archiveManifestFileName,
+		Mode: 0644,
+		Size: int64(len(data)),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	if err != nil {
+		return err
+	}
+	for _, f := range manifest.Files {
+		if f.Path == util.DefaultEnvironmentConfigFileName {
+			err = writeArchiveData(tw, f, config)
+		} else {
+			err = writeArchiveEntry(tw, environmentDirectory, f)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+//isRunsDirectory checks if relative path is <component>/<version>/runs (or pulls) directory
+func isRunsDirectory(rel string) bool {
+	parts := strings.Split(rel, "/")
+	return len(parts) == 3 && (parts[2] == util.DefaultComponentRunsSubdirectory || parts[2] == util.DefaultComponentPullsSubdirectory)
+}
+
+//validatePathElement checks if value taken from archived config can be safely used as single element of path
+func validatePathElement(kind string, value string) error {
+	if value == "" || value == "." || value == ".." || strings.ContainsAny(value, "/\\") {
+		return errors.New(fmt.Sprintf("incorrect %s %q in archived environment config", kind, value))
+	}
+	return nil
+}
+
+func writeArchiveData(tw *tar.Writer, f archiveFile, data []byte) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:     path.Join(archiveEnvironmentDir, f.Path),
+		Mode:     int64(f.Mode),
+		Typeflag: tar.TypeReg,
+		Size:     int64(len(data)),
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	return err
+}
+
+func writeArchiveEntry(tw *tar.Writer, environmentDirectory string, f archiveFile) error {
+	header := &tar.Header{
+		Name: path.Join(archiveEnvironmentDir, f.Path),
+		Mode: int64(f.Mode),
+	}
+	switch f.Type {
+	case archiveTypeDir:
+		header.Typeflag = tar.TypeDir
+		header.Name += "/"
+		return tw.WriteHeader(header)
+	case archiveTypeSymlink:
+		header.Typeflag = tar.TypeSymlink
+		header.Linkname = f.Link
+		return tw.WriteHeader(header)
+	}
+	header.Typeflag = tar.TypeReg
+	header.Size = f.Size
+	err := tw.WriteHeader(header)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path.Join(environmentDirectory, f.Path))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.CopyN(tw, file, f.Size)
+	return err
+}
+
+func fileChecksum(p string) (string, error) {
+	file, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	h := sha256.New()
+	_, err = io.Copy(h, file)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func hashHex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+//Import reads archive created by Export from r, verifies its integrity and creates Environment from it. If
+//environment with archived UUID already exists ErrUUIDCollision is returned unless rekey is set, in which case
+//imported environment gets new UUID. Imported mounts are pushed to shared storage (if it's used).
+func Import(r io.Reader, rekey bool) (*Environment, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+
+	header, err := tr.Next()
+	if err != nil {
+		return nil, err
+	}
+	if header.Name != archiveManifestFileName {
+		return nil, errors.New(fmt.Sprintf("expected %s as first archive entry but found %s", archiveManifestFileName, header.Name))
+	}
+	data, err := ioutil.ReadAll(tr)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &archiveManifest{}
+	err = yaml.Unmarshal(data, manifest)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Kind != kindEnvironmentArchive {
+		return nil, errors.New(fmt.Sprintf("incorrect archive kind %s", manifest.Kind))
+	}
+
+	backend, err := getStorage()
+	if err != nil {
+		return nil, err
+	}
+	targetUuid := manifest.Uuid
+	_, err = backend.Read(configKey(manifest.Uuid))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	_, statErr := os.Stat(path.Join(util.UsedEnvironmentDirectory, manifest.Uuid.String()))
+	if err == nil || statErr == nil {
+		if !rekey {
+			return nil, ErrUUIDCollision
+		}
+		targetUuid = uuid.New()
+		debug("environment %s already exists, will import it as %s", manifest.Uuid.String(), targetUuid.String())
+	}
+
+	tempDirectory, err := ioutil.TempDir(util.UsedConfigurationDirectory, "import-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tempDirectory)
+	err = extractArchive(tr, manifest, tempDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	configFile := path.Join(tempDirectory, util.DefaultEnvironmentConfigFileName)
+	data, err = ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+	e, _, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if e.Uuid != manifest.Uuid {
+		return nil, errors.New(fmt.Sprintf("environment UUID %s doesn't match archive manifest UUID %s", e.Uuid, manifest.Uuid))
+	}
+	for _, ic := range e.Installed {
+		err = validatePathElement("component name", ic.Name)
+		if err != nil {
+			return nil, err
+		}
+		err = validatePathElement("component version", ic.Version)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if targetUuid != e.Uuid {
+		e.Uuid = targetUuid
+		for i := range e.Installed {
+			e.Installed[i].EnvironmentRef = targetUuid
+		}
+	}
+	//config is written with Save after directory is moved into place
+	err = os.Remove(configFile)
+	if err != nil {
+		return nil, err
+	}
+	for _, ic := range e.Installed {
+		componentPath := path.Join(tempDirectory, ic.Name, ic.Version)
+		util.EnsureDirectory(path.Join(componentPath, util.DefaultComponentRunsSubdirectory))
+		util.EnsureDirectory(path.Join(componentPath, util.DefaultComponentMountsSubdirectory))
+	}
+
+	err = os.Chmod(tempDirectory, 0755)
+	if err != nil {
+		return nil, err
+	}
+	environmentDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String())
+	debug("will try to move %s to %s", tempDirectory, environmentDirectory)
+	err = os.Rename(tempDirectory, environmentDirectory)
+	if err != nil {
+		return nil, err
+	}
+	err = e.Save()
+	if err != nil {
+		return nil, err
+	}
+	for i := range e.Installed {
+		err = e.Installed[i].pushMounts()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+//extractArchive extracts environment entries of archive into directory verifying them against manifest
+func extractArchive(tr *tar.Reader, manifest *archiveManifest, directory string) error {
+	expected := make(map[string]archiveFile)
+	for _, f := range manifest.Files {
+		expected[f.Path] = f
+	}
+	//symlinks are created after all other entries so no entry can be written through them
+	symlinks := make(map[string]string)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(header.Name, "/")
+		if !strings.HasPrefix(name, archiveEnvironmentDir+"/") {
+			return errors.New(fmt.Sprintf("unexpected archive entry %s", header.Name))
+		}
+		rel := strings.TrimPrefix(name, archiveEnvironmentDir+"/")
+		f, ok := expected[rel]
+		if !ok {
+			return errors.New(fmt.Sprintf("archive entry %s not found in manifest", header.Name))
+		}
+		delete(expected, rel)
+		if path.IsAbs(rel) || rel != path.Clean(rel) || strings.HasPrefix(rel, "../") {
+			return errors.New(fmt.Sprintf("incorrect archive entry path %s", header.Name))
+		}
+		target := path.Join(directory, rel)
+		switch f.Type {
+		case archiveTypeDir:
+			if header.Typeflag != tar.TypeDir {
+				return errors.New(fmt.Sprintf("archive entry %s is not a directory", header.Name))
+			}
+			err = os.MkdirAll(target, f.Mode|0700)
+		case archiveTypeSymlink:
+			if header.Typeflag != tar.TypeSymlink || header.Linkname != f.Link {
+				return errors.New(fmt.Sprintf("archive entry %s doesn't match manifest", header.Name))
+			}
+			symlinks[target] = f.Link
+		case archiveTypeFile:
+			if header.Typeflag != tar.TypeReg {
+				return errors.New(fmt.Sprintf("archive entry %s is not a regular file", header.Name))
+			}
+			err = extractFile(tr, f, target)
+		default:
+			err = errors.New(fmt.Sprintf("unknown type %s of archive entry %s", f.Type, header.Name))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for p := range expected {
+		return errors.New(fmt.Sprintf("archive entry %s listed in manifest is missing", p))
+	}
+	for target, link := range symlinks {
+		err := os.Symlink(link, target)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//extractFile writes file content from reader to target and verifies its checksum
+func extractFile(r io.Reader, f archiveFile, target string) error {
+	err := os.MkdirAll(path.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, f.Mode)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(file, h), r)
+	if err != nil {
+		return err
+	}
+	if n != f.Size || hex.EncodeToString(h.Sum(nil)) != f.Sha256 {
+		return errors.New(fmt.Sprintf("checksum mismatch of archive entry %s", f.Path))
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package environment
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/epiphany-platform/cli/pkg/util"
+	"github.com/google/uuid"
+)
+
+func TestEnvironment_ExportImport(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "archive")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	e, err := create("e1", uuid.MustParse("5b0e3a55-3f7c-4c52-8d0e-6f1f7d2a9c10"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Installed = []InstalledComponentVersion{
+		{EnvironmentRef: e.Uuid, Name: "c1", Type: "docker", Version: "v1", Image: "i1", Mounts: []string{}, Commands: []InstalledComponentCommand{}},
+	}
+	err = e.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	componentPath := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), "c1", "v1")
+	mountsPath := path.Join(componentPath, util.DefaultComponentMountsSubdirectory)
+	runsPath := path.Join(componentPath, util.DefaultComponentRunsSubdirectory)
+	util.EnsureDirectory(path.Join(mountsPath, "terraform"))
+	util.EnsureDirectory(runsPath)
+	_ = ioutil.WriteFile(path.Join(mountsPath, "terraform", "state"), []byte("state"), 0600)
+	_ = ioutil.WriteFile(path.Join(runsPath, "run.log"), []byte("log"), 0644)
+	_ = os.Symlink("terraform/state", path.Join(mountsPath, "link"))
+
+	tests := []struct {
+		name        string
+		includeRuns bool
+	}{
+		{
+			name:        "without runs",
+			includeRuns: false,
+		},
+		{
+			name:        "with runs",
+			includeRuns: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := e.Export(&b, tt.includeRuns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			archive := b.Bytes()
+
+			_, err = Import(bytes.NewReader(archive), false)
+			if err != ErrUUIDCollision {
+				t.Fatalf("got error %v, want %v", err, ErrUUIDCollision)
+			}
+			got, err := Import(bytes.NewReader(archive), true)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Uuid == e.Uuid || got.Name != "e1" {
+				t.Errorf("got environment %s %s", got.Name, got.Uuid)
+			}
+			loaded, err := Get(got.Uuid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(loaded.Installed) != 1 || loaded.Installed[0].EnvironmentRef != got.Uuid {
+				t.Errorf("got installed %#v", loaded.Installed)
+			}
+			importedPath := path.Join(util.UsedEnvironmentDirectory, got.Uuid.String(), "c1", "v1")
+			data, err := ioutil.ReadFile(path.Join(importedPath, util.DefaultComponentMountsSubdirectory, "link"))
+			if err != nil || string(data) != "state" {
+				t.Errorf("got mounts content %q, error %v", data, err)
+			}
+			info, err := os.Stat(path.Join(importedPath, util.DefaultComponentMountsSubdirectory, "terraform", "state"))
+			if err != nil || info.Mode().Perm() != 0600 {
+				t.Errorf("got mounts file info %v, error %v", info, err)
+			}
+			_, err = os.Stat(path.Join(importedPath, util.DefaultComponentRunsSubdirectory, "run.log"))
+			if (err == nil) != tt.includeRuns {
+				t.Errorf("got runs file error %v, want present %t", err, tt.includeRuns)
+			}
+			if _, err := os.Stat(path.Join(importedPath, util.DefaultComponentRunsSubdirectory)); err != nil {
+				t.Errorf("expected runs directory to exist but got %v", err)
+			}
+		})
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		var b bytes.Buffer
+		err := e.Export(&b, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tampered := rewriteArchive(t, b.Bytes(), "environment/c1/v1/mounts/terraform/state", []byte("STATE"))
+		_, err = Import(bytes.NewReader(tampered), true)
+		if isWrongResult(t, err, errors.New("checksum mismatch of archive entry c1/v1/mounts/terraform/state")) {
+			return
+		}
+	})
+}
+
+func TestImport_incorrectInstalled(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "archive-installed")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	tests := []struct {
+		name    string
+		cName   string
+		version string
+		wantErr error
+	}{
+		{
+			name:    "version outside environment",
+			cName:   "c1",
+			version: "../../..",
+			wantErr: errors.New("incorrect component version \"../../..\" in archived environment config"),
+		},
+		{
+			name:    "name with backslash",
+			cName:   "c1\\c2",
+			version: "v1",
+			wantErr: errors.New(`incorrect component name "c1\\\\c2" in archived environment config`),
+		},
+		{
+			name:    "parent directory name",
+			cName:   "..",
+			version: "v1",
+			wantErr: errors.New("incorrect component name \"..\" in archived environment config"),
+		},
+		{
+			name:    "empty version",
+			cName:   "c1",
+			version: "",
+			wantErr: errors.New("incorrect component version \"\" in archived environment config"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := create("e1", uuid.New())
+			if err != nil {
+				t.Fatal(err)
+			}
+			e.Installed = []InstalledComponentVersion{
+				{EnvironmentRef: e.Uuid, Name: tt.cName, Type: "docker", Version: tt.version, Image: "i1"},
+			}
+			err = e.Save()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b bytes.Buffer
+			err = e.Export(&b, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries, err := ioutil.ReadDir(util.UsedConfigurationDirectory)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = Import(bytes.NewReader(b.Bytes()), true)
+			if isWrongResult(t, err, tt.wantErr) {
+				return
+			}
+			after, err := ioutil.ReadDir(util.UsedConfigurationDirectory)
+			if err != nil || len(after) != len(entries) {
+				t.Errorf("expected configuration directory to be unchanged but got %v (%v)", after, err)
+			}
+		})
+	}
+}
+
+//rewriteArchive replaces content of single entry of gzipped tar archive
+func rewriteArchive(t *testing.T, archive []byte, name string, content []byte) []byte {
+	gr, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	var b bytes.Buffer
+	gw := gzip.NewWriter(&b)
+	tw := tar.NewWriter(gw)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Name == name {
+			data = content
+			header.Size = int64(len(content))
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = tw.Close()
+	_ = gw.Close()
+	return b.Bytes()
+}
@@ -0,0 +1,98 @@
+package environment
+
+import (
+	"strings"
+
+	"github.com/epiphany-platform/cli/pkg/registry"
+	"github.com/epiphany-platform/cli/pkg/runtime"
+)
+
+//ImageStatus is result of comparison of image tag with Digest recorded at install time
+type ImageStatus string
+
+const (
+	ImageStatusOk       ImageStatus = "ok"
+	ImageStatusDrift    ImageStatus = "drift"
+	ImageStatusUnpinned ImageStatus = "unpinned"
+	ImageStatusUnknown  ImageStatus = "unknown"
+)
+
+//ImageVerification holds result of VerifyImage
+type ImageVerification struct {
+	Name          string      `yaml:"name" json:"name"`
+	Version       string      `yaml:"version" json:"version"`
+	Image         string      `yaml:"image" json:"image"`
+	Digest        string      `yaml:"digest,omitempty" json:"digest,omitempty"`
+	CurrentDigest string      `yaml:"current_digest,omitempty" json:"current_digest,omitempty"`
+	Status        ImageStatus `yaml:"status" json:"status"`
+}
+
+//recordDigest sets Digest of InstalledComponentVersion to digest of its local image. Digest stays empty (with
+//warning) if it cannot be determined, i.e. for images imported from bundles.
+func (cv *InstalledComponentVersion) recordDigest(rt runtime.Runtime) {
+	cv.Digest = ""
+	info, err := rt.Inspect(cv.Image)
+	if err != nil {
+		warnRecordDigest(err, cv.Image)
+		return
+	}
+	cv.Digest = info.Digest(cv.Image)
+	if cv.Digest == "" {
+		warnNoDigest(cv.Image)
+	}
+}
+
+//runImage returns image reference used to run InstalledComponentVersion: image repository with recorded Digest if
+//there is one, otherwise Image
+func (cv *InstalledComponentVersion) runImage() string {
+	if cv.Digest == "" || strings.Contains(cv.Image, "@") {
+		return cv.Image
+	}
+	return runtime.ImageRepository(cv.Image) + "@" + cv.Digest
+}
+
+//VerifyImage compares digest image tag currently points to with Digest recorded at install time. Local image is
+//checked unless pull is set, in which case tag is pulled first to check what registry currently serves.
+func (cv *InstalledComponentVersion) VerifyImage(pull bool) (*ImageVerification, error) {
+	v := &ImageVerification{
+		Name:    cv.Name,
+		Version: cv.Version,
+		Image:   cv.Image,
+		Digest:  cv.Digest,
+		Status:  ImageStatusUnknown,
+	}
+	if cv.Type != "docker" {
+		return v, nil
+	}
+	rt, err := getRuntime()
+	if err != nil {
+		return nil, err
+	}
+	if pull {
+		auth, err := registry.Auth(cv.Image)
+		if err != nil {
+			return nil, err
+		}
+		_, err = rt.Pull(cv.Image, auth, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	info, err := rt.Inspect(cv.Image)
+	if err != nil {
+		debug("cannot inspect image %s: %v", cv.Image, err)
+	} else {
+		v.CurrentDigest = info.Digest(cv.Image)
+	}
+	switch {
+	case v.CurrentDigest == "":
+		v.Status = ImageStatusUnknown
+	case cv.Digest == "":
+		v.Status = ImageStatusUnpinned
+	case cv.Digest == v.CurrentDigest:
+		v.Status = ImageStatusOk
+	default:
+		v.Status = ImageStatusDrift
+	}
+	return v, nil
+}
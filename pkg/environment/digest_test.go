@@ -0,0 +1,125 @@
+package environment
+
+import (
+	"testing"
+
+	"github.com/epiphany-platform/cli/pkg/runtime"
+)
+
+func TestInstalledComponentVersion_runImage(t *testing.T) {
+	tests := []struct {
+		name   string
+		image  string
+		digest string
+		want   string
+	}{
+		{
+			name:  "without digest",
+			image: "hashicorp/terraform:0.12.28",
+			want:  "hashicorp/terraform:0.12.28",
+		},
+		{
+			name:   "with digest",
+			image:  "docker.io/hashicorp/terraform:0.12.28",
+			digest: "sha256:aaa",
+			want:   "hashicorp/terraform@sha256:aaa",
+		},
+		{
+			name:   "registry with port",
+			image:  "localhost:5000/terraform:0.12.28",
+			digest: "sha256:aaa",
+			want:   "localhost:5000/terraform@sha256:aaa",
+		},
+		{
+			name:   "image pinned in repository",
+			image:  "hashicorp/terraform@sha256:bbb",
+			digest: "sha256:aaa",
+			want:   "hashicorp/terraform@sha256:bbb",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cv := &InstalledComponentVersion{Image: tt.image, Digest: tt.digest}
+			if got := cv.runImage(); got != tt.want {
+				t.Errorf("got image %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstalledComponentVersion_VerifyImage(t *testing.T) {
+	tests := []struct {
+		name        string
+		digest      string
+		images      map[string]*runtime.ImageInfo
+		pull        bool
+		wantCurrent string
+		wantStatus  ImageStatus
+	}{
+		{
+			name:   "ok",
+			digest: "sha256:aaa",
+			images: map[string]*runtime.ImageInfo{
+				"hashicorp/terraform:0.12.28": {RepoDigests: []string{"hashicorp/terraform@sha256:aaa"}},
+			},
+			wantCurrent: "sha256:aaa",
+			wantStatus:  ImageStatusOk,
+		},
+		{
+			name:   "drift",
+			digest: "sha256:aaa",
+			images: map[string]*runtime.ImageInfo{
+				"hashicorp/terraform:0.12.28": {RepoDigests: []string{"hashicorp/terraform@sha256:bbb"}},
+			},
+			wantCurrent: "sha256:bbb",
+			wantStatus:  ImageStatusDrift,
+		},
+		{
+			name: "unpinned",
+			images: map[string]*runtime.ImageInfo{
+				"hashicorp/terraform:0.12.28": {RepoDigests: []string{"hashicorp/terraform@sha256:aaa"}},
+			},
+			wantCurrent: "sha256:aaa",
+			wantStatus:  ImageStatusUnpinned,
+		},
+		{
+			name:       "missing image",
+			digest:     "sha256:aaa",
+			images:     map[string]*runtime.ImageInfo{},
+			wantStatus: ImageStatusUnknown,
+		},
+		{
+			name:   "image without registry digest",
+			digest: "sha256:aaa",
+			images: map[string]*runtime.ImageInfo{
+				"hashicorp/terraform:0.12.28": {},
+			},
+			wantStatus: ImageStatusUnknown,
+		},
+		{
+			name:        "pulled missing image",
+			digest:      "sha256:aaa",
+			images:      map[string]*runtime.ImageInfo{},
+			pull:        true,
+			wantCurrent: "sha256:70974453d8d5a45a464e5d0920509a306001c4d67c6d993bfa0ff3c52f063d32",
+			wantStatus:  ImageStatusDrift,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := runtime.NewFake()
+			fake.Images = tt.images
+			usedRuntime = fake
+			defer func() { usedRuntime = nil }()
+
+			cv := &InstalledComponentVersion{Name: "c1", Type: "docker", Version: "0.1.0", Image: "hashicorp/terraform:0.12.28", Digest: tt.digest}
+			got, err := cv.VerifyImage(tt.pull)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != tt.wantStatus || got.Digest != tt.digest || got.CurrentDigest != tt.wantCurrent {
+				t.Errorf("got %#v", got)
+			}
+		})
+	}
+}
@@ -2,44 +2,143 @@ package environment
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path"
+	"sort"
+	"strings"
 	"time"
 
-	"github.com/epiphany-platform/cli/pkg/docker"
+	"github.com/epiphany-platform/cli/pkg/registry"
+	"github.com/epiphany-platform/cli/pkg/runtime"
+	"github.com/epiphany-platform/cli/pkg/schema"
+	"github.com/epiphany-platform/cli/pkg/storage"
 	"github.com/epiphany-platform/cli/pkg/util"
 	"github.com/google/uuid"
 	"gopkg.in/yaml.v2"
 )
 
+const (
+	KindEnvironment = "Environment"
+)
+
+var (
+	//environmentSchema lists migrations of Environment config. Versions before v1 had no version and kind fields.
+	environmentSchema = &schema.Schema{
+		Kind:    KindEnvironment,
+		Current: "v1",
+		Migrations: []schema.Migration{
+			{From: schema.LegacyVersion, To: "v1"},
+		},
+	}
+)
+
+var (
+	usedRuntime       runtime.Runtime
+	usedStorage       storage.Backend
+	usedStorageConfig *storage.Config
+)
+
+//getRuntime returns runtime.Runtime selected with util.UsedRuntime. It's created on first use.
+func getRuntime() (runtime.Runtime, error) {
+	if usedRuntime == nil {
+		rt, err := runtime.New(util.UsedRuntime)
+		if err != nil {
+			return nil, err
+		}
+		usedRuntime = rt
+	}
+	return usedRuntime, nil
+}
+
+//UseStorage selects storage.Backend used to keep Environment configs. Nil config means local filesystem.
+func UseStorage(config *storage.Config) {
+	usedStorageConfig = config
+}
+
+//getStorage returns storage.Backend selected with UseStorage. Filesystem backend is rooted in
+//util.UsedEnvironmentDirectory.
+func getStorage() (storage.Backend, error) {
+	if usedStorage != nil {
+		return usedStorage, nil
+	}
+	return storage.New(usedStorageConfig, util.UsedEnvironmentDirectory)
+}
+
+//configKey returns storage key of Environment config
+func configKey(u uuid.UUID) string {
+	return u.String() + "/" + util.DefaultEnvironmentConfigFileName
+}
+
 //InstalledComponentCommand holds information about specific command of installed component
 type InstalledComponentCommand struct {
-	Name        string            `yaml:"name"`
-	Description string            `yaml:"description"`
-	Command     string            `yaml:"command"`
-	Envs        map[string]string `yaml:"envs"`
-	Args        []string          `yaml:"args"`
+	Name        string            `yaml:"name" json:"name"`
+	Description string            `yaml:"description" json:"description"`
+	Command     string            `yaml:"command" json:"command"`
+	Envs        map[string]string `yaml:"envs" json:"envs"`
+	Args        []string          `yaml:"args" json:"args"`
+	Interactive bool              `yaml:"interactive,omitempty" json:"interactive,omitempty"`
+}
+
+//RunOptions holds settings of single InstalledComponentVersion.Run call. Args are appended to command args
+//and Envs overlay command envs. Container is stopped after Timeout (if set) and killed when it doesn't finish
+//within GracePeriod after being stopped or after receiving forwarded signal.
+type RunOptions struct {
+	Interactive bool
+	Args        []string
+	Envs        map[string]string
+	Timeout     time.Duration
+	GracePeriod time.Duration
+}
+
+//effectiveArgs returns command args extended with args provided in RunOptions
+func (cc *InstalledComponentCommand) effectiveArgs(options RunOptions) []string {
+	args := make([]string, 0, len(cc.Args)+len(options.Args))
+	args = append(args, cc.Args...)
+	return append(args, options.Args...)
+}
+
+//effectiveEnvs returns command envs overlaid with envs provided in RunOptions
+func (cc *InstalledComponentCommand) effectiveEnvs(options RunOptions) map[string]string {
+	envs := make(map[string]string, len(cc.Envs)+len(options.Envs))
+	for k, v := range cc.Envs {
+		envs[k] = v
+	}
+	for k, v := range options.Envs {
+		envs[k] = v
+	}
+	return envs
 }
 
-//TODO add tests
-func (cc *InstalledComponentCommand) RunDocker(image string, workDirectory string, mountPath string, mounts []string) error {
+//RunContainer runs command in container using currently configured runtime.Runtime
+func (cc *InstalledComponentCommand) RunContainer(image string, workDirectory string, mountPath string, mounts []string, options RunOptions, stdout io.Writer, stderr io.Writer) error {
+	rt, err := getRuntime()
+	if err != nil {
+		return err
+	}
 	for _, m := range mounts {
 		util.EnsureDirectory(path.Join(mountPath, m))
 	}
-	dockerJob := &docker.Job{
+	job := runtime.Job{
 		Image:                image,
 		Command:              cc.Command,
-		Args:                 cc.Args,
+		Args:                 cc.effectiveArgs(options),
 		WorkDirectory:        workDirectory,
 		Mounts:               mounts,
 		MountPath:            mountPath,
-		EnvironmentVariables: cc.Envs,
+		EnvironmentVariables: cc.effectiveEnvs(options),
+		Interactive:          cc.Interactive || options.Interactive,
+		Timeout:              options.Timeout,
+		StopGracePeriod:      options.GracePeriod,
+		Stdout:               stdout,
+		Stderr:               stderr,
 	}
-	debug("will try to run docker job %+v", dockerJob)
-	return dockerJob.Run()
+	debug("will try to run job %+v", job)
+	return rt.Run(context.Background(), job)
 }
 
 //The String method is used to pretty-print InstalledComponentCommand struct
@@ -49,29 +148,72 @@ func (cc *InstalledComponentCommand) String() string {
 
 //InstalledComponentVersion struct holds information about installed components with its details.
 type InstalledComponentVersion struct {
-	EnvironmentRef uuid.UUID                   `yaml:"environment_ref"` //TODO try to remove it
-	Name           string                      `yaml:"name"`
-	Type           string                      `yaml:"type"`
-	Version        string                      `yaml:"version"`
-	Image          string                      `yaml:"image"`
-	WorkDirectory  string                      `yaml:"workdir"`
-	Mounts         []string                    `yaml:"mounts"`
-	Commands       []InstalledComponentCommand `yaml:"commands"`
-}
-
-//TODO add tests
-func (cv *InstalledComponentVersion) Run(command string) error {
+	EnvironmentRef uuid.UUID                   `yaml:"environment_ref" json:"environment_ref"`
+	Name           string                      `yaml:"name" json:"name"`
+	Type           string                      `yaml:"type" json:"type"`
+	Version        string                      `yaml:"version" json:"version"`
+	Image          string                      `yaml:"image" json:"image"`
+	Digest         string                      `yaml:"digest,omitempty" json:"digest,omitempty"`
+	WorkDirectory  string                      `yaml:"workdir" json:"workdir"`
+	Mounts         []string                    `yaml:"mounts" json:"mounts"`
+	Commands       []InstalledComponentCommand `yaml:"commands" json:"commands"`
+	UpgradedFrom   string                      `yaml:"upgraded_from,omitempty" json:"upgraded_from,omitempty"`
+}
+
+//Run executes command of InstalledComponentVersion. Command runs with TTY and attached stdin when it's marked as
+//interactive or when options request it. Image is run by Digest recorded at install time (if there is one). If
+//environments are kept in shared storage, mounts are pulled from it before run and pushed back after run.
+func (cv *InstalledComponentVersion) Run(command string, options RunOptions) error {
 	if cv.Type == "docker" {
-		mountPath := path.Join(
-			util.UsedEnvironmentDirectory,
-			cv.EnvironmentRef.String(),
-			cv.Name,
-			cv.Version,
-			util.DefaultComponentMountsSubdirectory,
-		)
+		mountPath := cv.mountsPath()
 		for _, cc := range cv.Commands {
 			if cc.Name == command {
-				return cc.RunDocker(cv.Image, cv.WorkDirectory, mountPath, cv.Mounts)
+				err := cv.pullMounts()
+				if err != nil {
+					return err
+				}
+				l, err := newRunLog(cv.runsPath(), cc.Name)
+				if err != nil {
+					return err
+				}
+				l.header("component", fmt.Sprintf("%s %s", cv.Name, cv.Version))
+				l.header("image", cv.runImage())
+				l.header("command", strings.Join(append([]string{cc.Command}, cc.effectiveArgs(options)...), " "))
+				if len(options.Envs) > 0 {
+					var keys []string
+					for k := range options.Envs {
+						keys = append(keys, k)
+					}
+					sort.Strings(keys)
+					l.header("env overrides", strings.Join(keys, " "))
+				}
+				if cc.Interactive || options.Interactive {
+					l.header("interactive", "true")
+				}
+				if options.Timeout > 0 {
+					l.header("timeout", options.Timeout.String())
+				}
+				l.header("started", time.Now().Format(time.RFC3339))
+				err = cc.RunContainer(
+					cv.runImage(),
+					cv.WorkDirectory,
+					mountPath,
+					cv.Mounts,
+					options,
+					io.MultiWriter(os.Stdout, l.writer("stdout")),
+					io.MultiWriter(os.Stderr, l.writer("stderr")),
+				)
+				if closeErr := l.close(err); closeErr != nil {
+					warnCloseRunLog(closeErr)
+				}
+				//mounts are pushed even if run failed as component could change its state partially
+				if pushErr := cv.pushMounts(); pushErr != nil {
+					if err == nil {
+						return pushErr
+					}
+					warnPushMounts(pushErr, cv.Name, cv.Version)
+				}
+				return err
 			}
 		}
 	}
@@ -82,35 +224,93 @@ func (cv *InstalledComponentVersion) Run(command string) error {
 func (cv *InstalledComponentVersion) String() string {
 	var b bytes.Buffer
 	b.WriteString(fmt.Sprintf("  Installed Component:\n   Name: %s\n   Type: %s\n   Version: %s\n   Image: %s\n", cv.Name, cv.Type, cv.Version, cv.Image))
+	if cv.Digest != "" {
+		b.WriteString(fmt.Sprintf("   Digest: %s\n", cv.Digest))
+	}
 	for _, cc := range cv.Commands {
 		b.WriteString(cc.String())
 	}
 	return b.String()
 }
 
-//TODO add tests
-func (cv *InstalledComponentVersion) Download() error {
+//Download pulls image of InstalledComponentVersion using currently configured runtime.Runtime rendering pull progress
+//to progress writer and records its Digest. If pull fails but image is already present locally (i.e. it was imported
+//from bundle) local image is used.
+func (cv *InstalledComponentVersion) Download(progress io.Writer) error {
 	if cv.Type == "docker" {
-		dockerImage := &docker.Image{Name: cv.Image}
-		logs, err := dockerImage.Pull()
-		cv.PersistLogs(logs)
+		rt, err := getRuntime()
 		if err != nil {
 			return err
 		}
+		auth, err := registry.Auth(cv.Image)
+		if err != nil {
+			return err
+		}
+		logs, err := rt.Pull(cv.Image, auth, progress)
+		cv.PersistLogs(logs)
+		if err != nil {
+			if _, inspectErr := rt.Inspect(cv.Image); inspectErr != nil {
+				return err
+			}
+			warnUsingLocalImage(cv.Image, err)
+		}
+		cv.recordDigest(rt)
 		return nil
 	}
 	return nil
 }
 
+//RemoveImage removes image used by InstalledComponentVersion
+func (cv *InstalledComponentVersion) RemoveImage() error {
+	if cv.Type == "docker" {
+		rt, err := getRuntime()
+		if err != nil {
+			return err
+		}
+		return rt.Remove(cv.Image)
+	}
+	return nil
+}
+
+//PurgeData removes runs and mounts directories of InstalledComponentVersion (and mounts kept in shared storage)
+func (cv *InstalledComponentVersion) PurgeData() error {
+	err := cv.deleteStoredMounts()
+	if err != nil {
+		return err
+	}
+	componentPath := path.Join(util.UsedEnvironmentDirectory, cv.EnvironmentRef.String(), cv.Name)
+	versionPath := path.Join(componentPath, cv.Version)
+	debug("will try to remove directory %s", versionPath)
+	err = os.RemoveAll(versionPath)
+	if err != nil {
+		return err
+	}
+	items, err := ioutil.ReadDir(componentPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if len(items) == 0 {
+		debug("will try to remove empty directory %s", componentPath)
+		return os.Remove(componentPath)
+	}
+	return nil
+}
+
+//PersistLogs writes image pull logs to pulls directory of InstalledComponentVersion (runs directory keeps only logs
+//of component runs)
 func (cv *InstalledComponentVersion) PersistLogs(logs string) { //TODO change to zerolog
-	logsPath := path.Join(
+	pullsPath := path.Join(
 		util.UsedEnvironmentDirectory,
 		cv.EnvironmentRef.String(),
 		cv.Name,
 		cv.Version,
-		util.DefaultComponentRunsSubdirectory,
-		fmt.Sprintf("%s.log", time.Now().Format("20060102-150405.000MST")),
+		util.DefaultComponentPullsSubdirectory,
 	)
+	util.EnsureDirectory(pullsPath)
+	logsPath := path.Join(pullsPath, fmt.Sprintf("%s.log", time.Now().Format(runLogTimeFormat)))
 	err := ioutil.WriteFile(logsPath, []byte(logs), 0644)
 	if err != nil {
 		errFailedToWriteFile(err)
@@ -119,9 +319,11 @@ func (cv *InstalledComponentVersion) PersistLogs(logs string) { //TODO change to
 
 //Environment struct holds all information about managed environment with list of InstalledComponentVersion
 type Environment struct {
-	Name      string                      `yaml:"name"`
-	Uuid      uuid.UUID                   `yaml:"uuid"`
-	Installed []InstalledComponentVersion `yaml:"installed"`
+	Version   string                      `yaml:"version" json:"version"`
+	Kind      string                      `yaml:"kind" json:"kind"`
+	Name      string                      `yaml:"name" json:"name"`
+	Uuid      uuid.UUID                   `yaml:"uuid" json:"uuid"`
+	Installed []InstalledComponentVersion `yaml:"installed" json:"installed"`
 }
 
 //Save updated Environment to file
@@ -129,18 +331,20 @@ func (e *Environment) Save() error {
 	if e.Uuid == uuid.Nil {
 		return errors.New(fmt.Sprintf("unexpected UUID on Save: %s", e.Uuid))
 	}
+	//config is always saved in current schema version
+	e.Version, e.Kind = environmentSchema.Current, KindEnvironment
 	debug("will try to marshal environment %+v", e)
 	data, err := yaml.Marshal(e)
 	if err != nil {
 		return err
 	}
-	ep := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), util.DefaultEnvironmentConfigFileName)
-	debug("will try to write marshaled data to file %s", ep)
-	err = ioutil.WriteFile(ep, data, 0644)
+	backend, err := getStorage()
 	if err != nil {
 		return err
 	}
-	return nil
+	key := configKey(e.Uuid)
+	debug("will try to write marshaled data to %s", key)
+	return backend.Write(key, data)
 }
 
 //The String method is used to pretty-print Environment struct
@@ -154,25 +358,122 @@ func (e *Environment) String() string {
 	return b.String()
 }
 
-//TODO add tests
+//Install downloads image of newComponent and adds it to Environment once download succeeded
 func (e *Environment) Install(newComponent InstalledComponentVersion) error {
 	for _, ic := range e.Installed {
 		if ic.Name == newComponent.Name && ic.Version == newComponent.Version {
 			return errors.New("this version of component is already installed in environment")
 		}
 	}
-	e.Installed = append(e.Installed, newComponent)
 	newComponentRunsDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), newComponent.Name, newComponent.Version, util.DefaultComponentRunsSubdirectory)
 	newComponentMountsDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), newComponent.Name, newComponent.Version, util.DefaultComponentMountsSubdirectory)
 	util.EnsureDirectory(newComponentRunsDirectory)
 	util.EnsureDirectory(newComponentMountsDirectory)
-	err := newComponent.Download()
+	err := newComponent.Download(os.Stdout)
 	if err != nil {
 		return err
 	}
+	e.Installed = append(e.Installed, newComponent)
 	return e.Save()
 }
 
+//Upgrade installs newComponent as next version of already installed component and migrates mounts of previous
+//version into it. Previous version is fromVersion or (if empty) the most recently installed version of component.
+//Mounts are copied unless moveMounts is set. Previous version stays installed so it can be uninstalled later.
+func (e *Environment) Upgrade(newComponent InstalledComponentVersion, fromVersion string, moveMounts bool) (*InstalledComponentVersion, error) {
+	var previous *InstalledComponentVersion
+	for i, ic := range e.Installed {
+		if ic.Name == newComponent.Name && (fromVersion == "" || ic.Version == fromVersion) {
+			previous = &e.Installed[i]
+		}
+	}
+	if previous == nil {
+		return nil, errors.New("no such component installed")
+	}
+	if previous.Version == newComponent.Version {
+		return nil, errors.New(fmt.Sprintf("component %s is already in version %s", previous.Name, previous.Version))
+	}
+	for _, ic := range e.Installed {
+		if ic.Name == newComponent.Name && ic.Version == newComponent.Version {
+			return nil, errors.New("this version of component is already installed in environment")
+		}
+	}
+	from := *previous
+	newComponent.UpgradedFrom = from.Version
+	err := from.pullMounts()
+	if err != nil {
+		return nil, err
+	}
+
+	componentPath := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), newComponent.Name)
+	previousMountsDirectory := path.Join(componentPath, from.Version, util.DefaultComponentMountsSubdirectory)
+	newMountsDirectory := path.Join(componentPath, newComponent.Version, util.DefaultComponentMountsSubdirectory)
+	util.EnsureDirectory(previousMountsDirectory)
+	util.EnsureDirectory(path.Join(componentPath, newComponent.Version))
+	var rollback func()
+	if moveMounts {
+		debug("will try to move mounts from %s to %s", previousMountsDirectory, newMountsDirectory)
+		err := os.Rename(previousMountsDirectory, newMountsDirectory)
+		if err != nil {
+			return nil, err
+		}
+		util.EnsureDirectory(previousMountsDirectory)
+		rollback = func() {
+			_ = os.RemoveAll(previousMountsDirectory)
+			_ = os.Rename(newMountsDirectory, previousMountsDirectory)
+		}
+	} else {
+		err := util.CopyDirectory(previousMountsDirectory, newMountsDirectory)
+		if err != nil {
+			_ = os.RemoveAll(path.Join(componentPath, newComponent.Version))
+			return nil, err
+		}
+		rollback = func() {
+			_ = os.RemoveAll(path.Join(componentPath, newComponent.Version))
+		}
+	}
+
+	err = e.Install(newComponent)
+	if err != nil {
+		warnUpgradeRollback(err)
+		rollback()
+		return nil, err
+	}
+	err = newComponent.pushMounts()
+	if err == nil && moveMounts {
+		err = from.pushMounts()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &from, nil
+}
+
+//Uninstall removes InstalledComponentVersion with given name and version from Environment and returns removed
+//element. If version is empty it's allowed only when there is exactly one version of component installed.
+func (e *Environment) Uninstall(name string, version string) (*InstalledComponentVersion, error) {
+	index := -1
+	for i, ic := range e.Installed {
+		if ic.Name == name && (version == "" || ic.Version == version) {
+			if index >= 0 {
+				return nil, errors.New("multiple versions of component installed, version has to be provided")
+			}
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, errors.New("no such component installed")
+	}
+	removed := e.Installed[index]
+	debug("will try to uninstall component %s %s from environment %s", removed.Name, removed.Version, e.Uuid.String())
+	e.Installed = append(e.Installed[:index], e.Installed[index+1:]...)
+	err := e.Save()
+	if err != nil {
+		return nil, err
+	}
+	return &removed, nil
+}
+
 //GetComponentByName returns first InstalledComponentVersion found by name
 func (e *Environment) GetComponentByName(name string) (*InstalledComponentVersion, error) {
 	for _, ic := range e.Installed {
@@ -183,6 +484,91 @@ func (e *Environment) GetComponentByName(name string) (*InstalledComponentVersio
 	return nil, errors.New("no such component installed")
 }
 
+//Delete removes Environment directory with all installed components data
+func (e *Environment) Delete() error {
+	if e.Uuid == uuid.Nil {
+		return errors.New(fmt.Sprintf("unexpected UUID on Delete: %s", e.Uuid))
+	}
+	for i := range e.Installed {
+		err := e.Installed[i].deleteStoredMounts()
+		if err != nil {
+			return err
+		}
+	}
+	backend, err := getStorage()
+	if err != nil {
+		return err
+	}
+	key := configKey(e.Uuid)
+	debug("will try to delete %s", key)
+	err = backend.Delete(key)
+	if err != nil {
+		return err
+	}
+	environmentDirectory := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String())
+	debug("will try to remove directory %s", environmentDirectory)
+	return os.RemoveAll(environmentDirectory)
+}
+
+//Rename changes Environment name and saves it
+func (e *Environment) Rename(name string) error {
+	if name == "" {
+		return errors.New("empty environment name")
+	}
+	debug("will try to rename environment %s from %s to %s", e.Uuid.String(), e.Name, name)
+	e.Name = name
+	return e.Save()
+}
+
+//Clone creates new Environment with given name and new UUID containing copy of all InstalledComponentVersion of e.
+//If copyMounts is set content of mounts directory of each component is copied as well (including shared storage).
+//Runs are never copied.
+func (e *Environment) Clone(name string, copyMounts bool) (*Environment, error) {
+	if name == "" {
+		return nil, errors.New("empty environment name")
+	}
+	clone, err := create(name, uuid.New())
+	if err != nil {
+		return nil, err
+	}
+	debug("will try to clone environment %s into %s", e.Uuid.String(), clone.Uuid.String())
+	for i := range e.Installed {
+		if copyMounts {
+			err = e.Installed[i].pullMounts()
+			if err != nil {
+				_ = clone.Delete()
+				return nil, err
+			}
+		}
+		ic := e.Installed[i]
+		ic.EnvironmentRef = clone.Uuid
+		clone.Installed = append(clone.Installed, ic)
+		sourcePath := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), ic.Name, ic.Version)
+		clonePath := path.Join(util.UsedEnvironmentDirectory, clone.Uuid.String(), ic.Name, ic.Version)
+		util.EnsureDirectory(path.Join(clonePath, util.DefaultComponentRunsSubdirectory))
+		cloneMountsDirectory := path.Join(clonePath, util.DefaultComponentMountsSubdirectory)
+		sourceMountsDirectory := path.Join(sourcePath, util.DefaultComponentMountsSubdirectory)
+		if _, err := os.Stat(sourceMountsDirectory); copyMounts && err == nil {
+			err = util.CopyDirectory(sourceMountsDirectory, cloneMountsDirectory)
+			if err == nil {
+				err = ic.pushMounts()
+			}
+			if err != nil {
+				_ = clone.Delete()
+				return nil, err
+			}
+		} else {
+			util.EnsureDirectory(cloneMountsDirectory)
+		}
+	}
+	err = clone.Save()
+	if err != nil {
+		_ = clone.Delete()
+		return nil, err
+	}
+	return clone, nil
+}
+
 //Create new environment with given name
 func Create(name string) (*Environment, error) {
 	return create(name, uuid.New())
@@ -206,16 +592,25 @@ func create(name string, uuid uuid.UUID) (*Environment, error) {
 
 //GetAll existing Environment
 func GetAll() ([]*Environment, error) {
-	debug("will try to get all subdirectories of %s directory", util.UsedEnvironmentDirectory)
-	items, err := ioutil.ReadDir(util.UsedEnvironmentDirectory)
+	backend, err := getStorage()
+	if err != nil {
+		return nil, err
+	}
+	debug("will try to list all environments in storage")
+	items, err := backend.List("")
 	if err != nil {
 		return nil, err
 	}
 	var environments []*Environment
 	for _, i := range items {
-		debug("entered directory %s", i.Name())
-		if i.IsDir() {
-			e, err := Get(uuid.MustParse(i.Name()))
+		debug("entered %s", i)
+		if strings.HasSuffix(i, "/") {
+			u, err := uuid.Parse(strings.TrimSuffix(i, "/"))
+			if err != nil {
+				warnNotEnvironmentDirectory(err)
+				continue
+			}
+			e, err := Get(u)
 			if err == nil {
 				environments = append(environments, e)
 			} else {
@@ -226,27 +621,108 @@ func GetAll() ([]*Environment, error) {
 	return environments, nil
 }
 
+//Find Environment by reference which can be full UUID, environment name or unique UUID prefix (in that order of
+//precedence). It returns error if reference matches multiple environments.
+func Find(reference string) (*Environment, error) {
+	if reference == "" {
+		return nil, errors.New("empty environment reference")
+	}
+	if u, err := uuid.Parse(reference); err == nil {
+		return Get(u)
+	}
+	environments, err := GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var byName, byPrefix []*Environment
+	for _, e := range environments {
+		if e.Name == reference {
+			byName = append(byName, e)
+		}
+		if strings.HasPrefix(e.Uuid.String(), strings.ToLower(reference)) {
+			byPrefix = append(byPrefix, e)
+		}
+	}
+	for _, matches := range [][]*Environment{byName, byPrefix} {
+		if len(matches) == 1 {
+			debug("environment reference %s resolved to %s", reference, matches[0].Uuid.String())
+			return matches[0], nil
+		}
+		if len(matches) > 1 {
+			var uuids []string
+			for _, e := range matches {
+				uuids = append(uuids, e.Uuid.String())
+			}
+			sort.Strings(uuids)
+			return nil, errors.New(fmt.Sprintf("environment reference %s is ambiguous (matches: %s)", reference, strings.Join(uuids, ", ")))
+		}
+	}
+	return nil, errors.New(fmt.Sprintf("no environment matches %s", reference))
+}
+
+//IsImageUsed checks if any InstalledComponentVersion in any existing Environment uses provided image
+func IsImageUsed(image string) (bool, error) {
+	environments, err := GetAll()
+	if err != nil {
+		return false, err
+	}
+	for _, e := range environments {
+		for _, ic := range e.Installed {
+			if ic.Image == image {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
 //Get Environment bu uuid
 func Get(uuid uuid.UUID) (*Environment, error) {
-	expectedFile := path.Join(util.UsedEnvironmentDirectory, uuid.String(), util.DefaultEnvironmentConfigFileName)
-	debug("will try to get environment config from file %s", expectedFile)
-	if _, err := os.Stat(expectedFile); os.IsNotExist(err) {
-		warnEnvironmentConfigFileNotFound(err, expectedFile)
+	backend, err := getStorage()
+	if err != nil {
 		return nil, err
-	} else {
-		e := &Environment{}
-		debug("trying to open %s file", expectedFile)
-		file, err := os.Open(expectedFile)
+	}
+	key := configKey(uuid)
+	debug("will try to get environment config %s", key)
+	data, err := backend.Read(key)
+	if os.IsNotExist(err) {
+		warnEnvironmentConfigFileNotFound(err, key)
+		return nil, err
+	} else if err != nil {
+		return nil, err
+	}
+	e, version, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	if version != environmentSchema.Current {
+		backupKey := fmt.Sprintf("%s.%s.bak", key, version)
+		infoMigrated(key, version, environmentSchema.Current, backupKey)
+		err = backend.Write(backupKey, data)
 		if err != nil {
 			return nil, err
 		}
-		defer file.Close()
-		d := yaml.NewDecoder(file)
-		debug("will try to decode file %s to yaml", expectedFile)
-		if err := d.Decode(&e); err != nil {
+		err = e.Save()
+		if err != nil {
 			return nil, err
 		}
-		debug("got environment config %+v", e)
-		return e, nil
 	}
+	debug("got environment config %+v", e)
+	//environment stored remotely might not have local directory for mounts and runs yet
+	util.EnsureDirectory(path.Join(util.UsedEnvironmentDirectory, uuid.String()))
+	return e, nil
+}
+
+//decode Environment config migrating it to current schema version. It returns version config had before migration.
+func decode(data []byte) (*Environment, string, error) {
+	migrated, version, err := environmentSchema.Upgrade(data)
+	if err != nil {
+		return nil, "", err
+	}
+	e := &Environment{}
+	debug("will try to decode environment config")
+	if err := yaml.Unmarshal(migrated, e); err != nil {
+		return nil, "", err
+	}
+	return e, version, nil
 }
@@ -2,6 +2,7 @@ package environment
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -11,6 +12,8 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/epiphany-platform/cli/pkg/runtime"
+	"github.com/epiphany-platform/cli/pkg/storage"
 	"github.com/epiphany-platform/cli/pkg/util"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog"
@@ -58,6 +61,8 @@ func TestGet(t *testing.T) {
 uuid: fccf6810-32c4-4500-9414-2de45d2c4097
 installed: []`),
 			want: &Environment{
+				Version:   "v1",
+				Kind:      KindEnvironment,
 				Name:      "e1",
 				Uuid:      uuid.MustParse("fccf6810-32c4-4500-9414-2de45d2c4097"),
 				Installed: []InstalledComponentVersion{},
@@ -79,6 +84,17 @@ installed: []`),
 			mocked:  []byte(`incorrect file`),
 			wantErr: errors.New("yaml: unmarshal errors:\n  line 1: cannot unmarshal !!str `incorre...` into environment.Environment"),
 		},
+		{
+			name: "newer version",
+			args: args{
+				uuid: uuid.MustParse("2b6f3c1d-8e4a-4b7c-9d2e-5f6a7b8c9d0e"),
+			},
+			mocked: []byte(`version: v2
+kind: Environment
+name: e1
+uuid: 2b6f3c1d-8e4a-4b7c-9d2e-5f6a7b8c9d0e`),
+			wantErr: errors.New("Environment file has schema version v2 but this version of e supports only up to v1, please upgrade e"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -138,11 +154,15 @@ installed: []`),
 			},
 			want: []*Environment{
 				{
+					Version:   "v1",
+					Kind:      KindEnvironment,
 					Name:      "e2",
 					Uuid:      uuid.MustParse("45764648-162a-4526-bdd0-71a438fd6ceb"),
 					Installed: []InstalledComponentVersion{},
 				},
 				{
+					Version:   "v1",
+					Kind:      KindEnvironment,
 					Name:      "e1",
 					Uuid:      uuid.MustParse("4af1705c-c48f-4ca2-be08-53c673da835c"),
 					Installed: []InstalledComponentVersion{},
@@ -169,8 +189,17 @@ uuid: 4af1705c-c48f-4ca2-be08-53c673da835c
 installed: []`),
 				},
 			},
-			wantErr:     errors.New("uuid: Parse\\(incorrect-directory-name\\): invalid UUID length: 24"),
-			shouldPanic: true,
+			want: []*Environment{
+				{
+					Version:   "v1",
+					Kind:      KindEnvironment,
+					Name:      "e2",
+					Uuid:      uuid.MustParse("45764648-162a-4526-bdd0-71a438fd6ceb"),
+					Installed: []InstalledComponentVersion{},
+				},
+			},
+			wantErr:     nil,
+			shouldPanic: false,
 		},
 		{
 			name: "incorrect config file name",
@@ -192,6 +221,8 @@ installed: []`),
 			},
 			want: []*Environment{
 				{
+					Version:   "v1",
+					Kind:      KindEnvironment,
 					Name:      "e2",
 					Uuid:      uuid.MustParse("45764648-162a-4526-bdd0-71a438fd6ceb"),
 					Installed: []InstalledComponentVersion{},
@@ -218,6 +249,8 @@ installed: []`),
 			},
 			want: []*Environment{
 				{
+					Version:   "v1",
+					Kind:      KindEnvironment,
 					Name:      "e2",
 					Uuid:      uuid.MustParse("45764648-162a-4526-bdd0-71a438fd6ceb"),
 					Installed: []InstalledComponentVersion{},
@@ -286,8 +319,10 @@ func Test_create(t *testing.T) {
 				uuid: "b03bb900-5d49-4421-a45e-eeeb40e0a5d5",
 			},
 			want: &Environment{
-				Name: "e1",
-				Uuid: uuid.MustParse("b03bb900-5d49-4421-a45e-eeeb40e0a5d5"),
+				Version: "v1",
+				Kind:    KindEnvironment,
+				Name:    "e1",
+				Uuid:    uuid.MustParse("b03bb900-5d49-4421-a45e-eeeb40e0a5d5"),
 			},
 			wantErr: nil,
 		},
@@ -298,7 +333,9 @@ func Test_create(t *testing.T) {
 				uuid: "66d4cd70-4375-4737-b6ce-7e13f3cc93f9",
 			},
 			want: &Environment{
-				Uuid: uuid.MustParse("66d4cd70-4375-4737-b6ce-7e13f3cc93f9"),
+				Version: "v1",
+				Kind:    KindEnvironment,
+				Uuid:    uuid.MustParse("66d4cd70-4375-4737-b6ce-7e13f3cc93f9"),
 			},
 			wantErr: nil,
 		},
@@ -337,7 +374,9 @@ func TestEnvironment_Save(t *testing.T) {
 				Name: "e1",
 				Uuid: uuid.MustParse("10d52c05-029e-4794-a790-79d6c2af40b6"),
 			},
-			wantContent: []byte(`name: e1
+			wantContent: []byte(`version: v1
+kind: Environment
+name: e1
 uuid: 10d52c05-029e-4794-a790-79d6c2af40b6
 installed: []
 `),
@@ -355,7 +394,9 @@ installed: []
 			environment: &Environment{
 				Uuid: uuid.MustParse("10d52c05-029e-4794-a790-79d6c2af40b6"),
 			},
-			wantContent: []byte(`name: ""
+			wantContent: []byte(`version: v1
+kind: Environment
+name: ""
 uuid: 10d52c05-029e-4794-a790-79d6c2af40b6
 installed: []
 `),
@@ -379,7 +420,9 @@ installed: []
 					},
 				},
 			},
-			wantContent: []byte(`name: x
+			wantContent: []byte(`version: v1
+kind: Environment
+name: x
 uuid: 3e5b7269-1b3d-4003-9454-9f472857633a
 installed:
 - environment_ref: 3e5b7269-1b3d-4003-9454-9f472857633a
@@ -541,6 +584,453 @@ func TestEnvironment_GetComponentByName(t *testing.T) {
 	}
 }
 
+func TestInstalledComponentCommand_effective(t *testing.T) {
+	cc := &InstalledComponentCommand{
+		Command: "apply",
+		Args:    []string{"-auto-approve"},
+		Envs: map[string]string{
+			"TF_LOG": "WARN",
+			"A":      "a",
+		},
+	}
+	tests := []struct {
+		name     string
+		options  RunOptions
+		wantArgs []string
+		wantEnvs map[string]string
+	}{
+		{
+			name:     "no overrides",
+			options:  RunOptions{},
+			wantArgs: []string{"-auto-approve"},
+			wantEnvs: map[string]string{"TF_LOG": "WARN", "A": "a"},
+		},
+		{
+			name: "with overrides",
+			options: RunOptions{
+				Args: []string{"-target=module.x"},
+				Envs: map[string]string{"TF_LOG": "DEBUG", "B": "b"},
+			},
+			wantArgs: []string{"-auto-approve", "-target=module.x"},
+			wantEnvs: map[string]string{"TF_LOG": "DEBUG", "A": "a", "B": "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cc.effectiveArgs(tt.options); !reflect.DeepEqual(got, tt.wantArgs) {
+				t.Errorf("got args = %#v, want %#v", got, tt.wantArgs)
+			}
+			if got := cc.effectiveEnvs(tt.options); !reflect.DeepEqual(got, tt.wantEnvs) {
+				t.Errorf("got envs = %#v, want %#v", got, tt.wantEnvs)
+			}
+			if !reflect.DeepEqual(cc.Args, []string{"-auto-approve"}) || cc.Envs["TF_LOG"] != "WARN" {
+				t.Errorf("command was modified: %#v", cc)
+			}
+		})
+	}
+}
+
+func TestInstalledComponentVersion_Run(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "run")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	fake := runtime.NewFake()
+	usedRuntime = fake
+	defer func() { usedRuntime = nil }()
+
+	cv := &InstalledComponentVersion{
+		EnvironmentRef: uuid.MustParse("10d52c05-029e-4794-a790-79d6c2af40b6"),
+		Name:           "c1",
+		Type:           "docker",
+		Version:        "v1",
+		Image:          "i1",
+		WorkDirectory:  "/w",
+		Mounts:         []string{"/m"},
+		Commands: []InstalledComponentCommand{
+			{
+				Name:    "apply",
+				Command: "apply",
+				Args:    []string{"-auto-approve"},
+				Envs:    map[string]string{"TF_LOG": "WARN"},
+			},
+		},
+	}
+
+	err := cv.Run("unknown", RunOptions{})
+	if isWrongResult(t, err, errors.New("nothing to run for this version")) {
+		return
+	}
+
+	err = cv.Run("apply", RunOptions{Args: []string{"-target=x"}, Envs: map[string]string{"TF_LOG": "DEBUG"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.Jobs) != 1 {
+		t.Fatalf("expected one job but got %#v", fake.Jobs)
+	}
+	job := fake.Jobs[0]
+	mountPath := path.Join(util.UsedEnvironmentDirectory, cv.EnvironmentRef.String(), "c1", "v1", util.DefaultComponentMountsSubdirectory)
+	if job.Image != "i1" || job.Command != "apply" || job.WorkDirectory != "/w" || job.MountPath != mountPath {
+		t.Errorf("got job %#v", job)
+	}
+	if !reflect.DeepEqual(job.Args, []string{"-auto-approve", "-target=x"}) {
+		t.Errorf("got args %#v", job.Args)
+	}
+	if !reflect.DeepEqual(job.EnvironmentVariables, map[string]string{"TF_LOG": "DEBUG"}) {
+		t.Errorf("got envs %#v", job.EnvironmentVariables)
+	}
+	if _, err := os.Stat(path.Join(mountPath, "m")); err != nil {
+		t.Errorf("expected mount directory to be created but got: %v", err)
+	}
+
+	runs, err := cv.GetRuns()
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("expected one run but got %#v (%v)", runs, err)
+	}
+	content, err := cv.GetRunLog(runs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := regexp.MustCompile(`(?s)# command: apply -auto-approve -target=x
+# env overrides: TF_LOG
+.*\[stdout\] apply
+# finished: `)
+	if !want.MatchString(content) {
+		t.Errorf("got run log \n%s\n", content)
+	}
+}
+
+func TestEnvironment_Install(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "install")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	fake := runtime.NewFake()
+	usedRuntime = fake
+	defer func() { usedRuntime = nil }()
+
+	e, err := create("e1", uuid.MustParse("10d52c05-029e-4794-a790-79d6c2af40b6"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := InstalledComponentVersion{EnvironmentRef: e.Uuid, Name: "c1", Type: "docker", Version: "v1", Image: "i1", Mounts: []string{}, Commands: []InstalledComponentCommand{}}
+	err = e.Install(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := fake.Inspect("i1")
+	if err != nil {
+		t.Fatalf("expected image to be pulled but got: %v", err)
+	}
+	got, err := Get(e.Uuid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := c
+	want.Digest = info.Digest("i1")
+	if want.Digest == "" || !reflect.DeepEqual(got.Installed, []InstalledComponentVersion{want}) {
+		t.Errorf("got installed %#v", got.Installed)
+	}
+	err = e.Install(c)
+	if isWrongResult(t, err, errors.New("this version of component is already installed in environment")) {
+		return
+	}
+
+	fake.PullErr = errors.New("pull failed")
+	err = e.Install(InstalledComponentVersion{EnvironmentRef: e.Uuid, Name: "c2", Type: "docker", Version: "v1", Image: "i2"})
+	if isWrongResult(t, err, errors.New("pull failed")) {
+		return
+	}
+	err = e.Install(InstalledComponentVersion{EnvironmentRef: e.Uuid, Name: "c1", Type: "docker", Version: "v2", Image: "i1"})
+	if err != nil {
+		t.Errorf("expected local image to be used when pull fails but got: %v", err)
+	}
+}
+
+func TestEnvironment_Uninstall(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "env-uninstall")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	tests := []struct {
+		name          string
+		installed     []InstalledComponentVersion
+		componentName string
+		version       string
+		want          *InstalledComponentVersion
+		wantInstalled []InstalledComponentVersion
+		wantErr       error
+	}{
+		{
+			name: "correct with version",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Version: "v1"},
+				{Name: "c1", Version: "v2"},
+			},
+			componentName: "c1",
+			version:       "v1",
+			want:          &InstalledComponentVersion{Name: "c1", Version: "v1"},
+			wantInstalled: []InstalledComponentVersion{
+				{Name: "c1", Version: "v2"},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "correct without version",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Version: "v1"},
+				{Name: "c2", Version: "v1"},
+			},
+			componentName: "c2",
+			want:          &InstalledComponentVersion{Name: "c2", Version: "v1"},
+			wantInstalled: []InstalledComponentVersion{
+				{Name: "c1", Version: "v1"},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "ambiguous without version",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Version: "v1"},
+				{Name: "c1", Version: "v2"},
+			},
+			componentName: "c1",
+			wantErr:       errors.New("multiple versions of component installed, version has to be provided"),
+		},
+		{
+			name: "missing version",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Version: "v1"},
+			},
+			componentName: "c1",
+			version:       "v2",
+			wantErr:       errors.New("no such component installed"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := uuid.MustParse("10d52c05-029e-4794-a790-79d6c2af40b6")
+			err := os.MkdirAll(path.Join(util.UsedEnvironmentDirectory, u.String()), 0755)
+			if err != nil {
+				t.Fatal(err)
+			}
+			e := &Environment{
+				Name:      "e1",
+				Uuid:      u,
+				Installed: tt.installed,
+			}
+			got, err := e.Uninstall(tt.componentName, tt.version)
+			if isWrongResult(t, err, tt.wantErr) {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %#v, want %#v", got, tt.want)
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(e.Installed, tt.wantInstalled) {
+				t.Errorf("installed = %#v, want %#v", e.Installed, tt.wantInstalled)
+			}
+		})
+	}
+}
+
+func TestEnvironment_Upgrade(t *testing.T) {
+	tests := []struct {
+		name        string
+		installed   []InstalledComponentVersion
+		newVersion  string
+		fromVersion string
+		move        bool
+		wantFrom    string
+		wantErr     error
+	}{
+		{
+			name: "copy mounts",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Type: "t", Version: "v1"},
+			},
+			newVersion: "v2",
+			wantFrom:   "v1",
+		},
+		{
+			name: "move mounts",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Type: "t", Version: "v1"},
+			},
+			newVersion: "v2",
+			move:       true,
+			wantFrom:   "v1",
+		},
+		{
+			name: "most recent previous version",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Type: "t", Version: "v1"},
+				{Name: "c1", Type: "t", Version: "v2"},
+			},
+			newVersion: "v3",
+			wantFrom:   "v2",
+		},
+		{
+			name: "explicit previous version",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Type: "t", Version: "v1"},
+				{Name: "c1", Type: "t", Version: "v2"},
+			},
+			newVersion:  "v3",
+			fromVersion: "v1",
+			wantFrom:    "v1",
+		},
+		{
+			name: "same version",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Type: "t", Version: "v1"},
+			},
+			newVersion: "v1",
+			wantErr:    errors.New("component c1 is already in version v1"),
+		},
+		{
+			name: "already installed",
+			installed: []InstalledComponentVersion{
+				{Name: "c1", Type: "t", Version: "v2"},
+				{Name: "c1", Type: "t", Version: "v1"},
+			},
+			newVersion: "v2",
+			wantErr:    errors.New("this version of component is already installed in environment"),
+		},
+		{
+			name:       "not installed",
+			newVersion: "v1",
+			wantErr:    errors.New("no such component installed"),
+		},
+	}
+	for _, tt := range tests {
+		util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "env-upgrade")
+		t.Run(tt.name, func(t *testing.T) {
+			u := uuid.MustParse("10d52c05-029e-4794-a790-79d6c2af40b6")
+			componentPath := path.Join(util.UsedEnvironmentDirectory, u.String(), "c1")
+			for i := range tt.installed {
+				tt.installed[i].EnvironmentRef = u
+				mountsPath := path.Join(componentPath, tt.installed[i].Version, util.DefaultComponentMountsSubdirectory)
+				util.EnsureDirectory(mountsPath)
+				err := ioutil.WriteFile(path.Join(mountsPath, "state"), []byte(tt.installed[i].Version), 0644)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			e := &Environment{
+				Name:      "e1",
+				Uuid:      u,
+				Installed: tt.installed,
+			}
+			got, err := e.Upgrade(InstalledComponentVersion{EnvironmentRef: u, Name: "c1", Type: "t", Version: tt.newVersion}, tt.fromVersion, tt.move)
+			if isWrongResult(t, err, tt.wantErr) {
+				return
+			}
+			if err != nil {
+				return
+			}
+			if got.Version != tt.wantFrom {
+				t.Errorf("got previous version %s, want %s", got.Version, tt.wantFrom)
+			}
+			upgraded := e.Installed[len(e.Installed)-1]
+			if upgraded.Version != tt.newVersion || upgraded.UpgradedFrom != tt.wantFrom {
+				t.Errorf("got upgraded component %#v", upgraded)
+			}
+			content, err := ioutil.ReadFile(path.Join(componentPath, tt.newVersion, util.DefaultComponentMountsSubdirectory, "state"))
+			if err != nil || string(content) != tt.wantFrom {
+				t.Errorf("expected mounts of %s in new version but got %s (%v)", tt.wantFrom, content, err)
+			}
+			_, err = os.Stat(path.Join(componentPath, tt.wantFrom, util.DefaultComponentMountsSubdirectory, "state"))
+			if tt.move && !os.IsNotExist(err) {
+				t.Errorf("expected mounts of previous version to be moved")
+			} else if !tt.move && err != nil {
+				t.Errorf("expected mounts of previous version to stay but got: %v", err)
+			}
+		})
+		os.RemoveAll(util.UsedConfigurationDirectory)
+	}
+}
+
+func TestInstalledComponentVersion_PurgeData(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "purge-data")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	u := uuid.MustParse("10d52c05-029e-4794-a790-79d6c2af40b6")
+	componentPath := path.Join(util.UsedEnvironmentDirectory, u.String(), "c1")
+	for _, v := range []string{"v1", "v2"} {
+		for _, d := range []string{util.DefaultComponentRunsSubdirectory, util.DefaultComponentMountsSubdirectory} {
+			err := os.MkdirAll(path.Join(componentPath, v, d), 0755)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	cv1 := &InstalledComponentVersion{EnvironmentRef: u, Name: "c1", Version: "v1"}
+	if err := cv1.PurgeData(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path.Join(componentPath, "v1")); !os.IsNotExist(err) {
+		t.Errorf("expected directory of version v1 to be removed")
+	}
+	if _, err := os.Stat(path.Join(componentPath, "v2")); err != nil {
+		t.Errorf("expected directory of version v2 to stay but got: %v", err)
+	}
+
+	cv2 := &InstalledComponentVersion{EnvironmentRef: u, Name: "c1", Version: "v2"}
+	if err := cv2.PurgeData(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(componentPath); !os.IsNotExist(err) {
+		t.Errorf("expected empty component directory to be removed")
+	}
+}
+
+func TestIsImageUsed(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "image-used")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	envDir := path.Join(util.UsedEnvironmentDirectory, "45764648-162a-4526-bdd0-71a438fd6ceb")
+	err := os.MkdirAll(envDir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(path.Join(envDir, util.DefaultEnvironmentConfigFileName), []byte(`name: e1
+uuid: 45764648-162a-4526-bdd0-71a438fd6ceb
+installed:
+- name: c1
+  image: i1
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		image string
+		want  bool
+	}{
+		{
+			name:  "used",
+			image: "i1",
+			want:  true,
+		},
+		{
+			name:  "not used",
+			image: "i2",
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := IsImageUsed(tt.image)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
 func isWrongResult(t *testing.T, err error, wantErr error) bool {
 	if err != nil && wantErr != nil {
 		re := regexp.MustCompile(wantErr.Error())
@@ -575,3 +1065,279 @@ func assertPanic(t *testing.T, f func(), wantErr error) {
 	}()
 	f()
 }
+
+func TestEnvironment_Rename(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "rename")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	e, err := create("e1", uuid.MustParse("4a1a1c5e-7e5b-4b0c-8c8c-0f4b5d6b3a11"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = e.Rename("")
+	if isWrongResult(t, err, errors.New("empty environment name")) {
+		return
+	}
+	err = e.Rename("e2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Get(e.Uuid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "e2" {
+		t.Errorf("got name %s, want e2", got.Name)
+	}
+}
+
+func TestEnvironment_Clone(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "clone")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	e, err := create("prod", uuid.MustParse("7d1f0e6c-2a43-4f3e-9a57-2c9b0d1e8f21"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Installed = []InstalledComponentVersion{
+		{EnvironmentRef: e.Uuid, Name: "c1", Type: "docker", Version: "v1", Image: "i1", Mounts: []string{}, Commands: []InstalledComponentCommand{}},
+	}
+	err = e.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sourcePath := path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), "c1", "v1")
+	util.EnsureDirectory(path.Join(sourcePath, util.DefaultComponentMountsSubdirectory))
+	util.EnsureDirectory(path.Join(sourcePath, util.DefaultComponentRunsSubdirectory))
+	_ = ioutil.WriteFile(path.Join(sourcePath, util.DefaultComponentMountsSubdirectory, "state"), []byte("s"), 0644)
+	_ = ioutil.WriteFile(path.Join(sourcePath, util.DefaultComponentRunsSubdirectory, "run.log"), []byte("r"), 0644)
+
+	tests := []struct {
+		name       string
+		copyMounts bool
+		wantState  bool
+	}{
+		{
+			name:       "without mounts",
+			copyMounts: false,
+			wantState:  false,
+		},
+		{
+			name:       "with mounts",
+			copyMounts: true,
+			wantState:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clone, err := e.Clone("staging", tt.copyMounts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Get(clone.Uuid)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Name != "staging" || got.Uuid == e.Uuid {
+				t.Errorf("got environment %s %s", got.Name, got.Uuid)
+			}
+			want := []InstalledComponentVersion{e.Installed[0]}
+			want[0].EnvironmentRef = clone.Uuid
+			if !reflect.DeepEqual(got.Installed, want) {
+				t.Errorf("got installed %#v, want %#v", got.Installed, want)
+			}
+			clonePath := path.Join(util.UsedEnvironmentDirectory, clone.Uuid.String(), "c1", "v1")
+			if _, err := os.Stat(path.Join(clonePath, util.DefaultComponentMountsSubdirectory, "state")); (err == nil) != tt.wantState {
+				t.Errorf("got mounts state file error %v, want present %t", err, tt.wantState)
+			}
+			if _, err := os.Stat(path.Join(clonePath, util.DefaultComponentRunsSubdirectory, "run.log")); !os.IsNotExist(err) {
+				t.Errorf("expected runs not to be copied but got %v", err)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "find")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	for _, e := range []struct {
+		name string
+		uuid string
+	}{
+		{name: "prod", uuid: "a1b2c3d4-0000-4000-8000-000000000001"},
+		{name: "staging", uuid: "a1b2ffff-0000-4000-8000-000000000002"},
+		{name: "dup", uuid: "b0000000-0000-4000-8000-000000000003"},
+		{name: "dup", uuid: "c0000000-0000-4000-8000-000000000004"},
+		{name: "c0", uuid: "d0000000-0000-4000-8000-000000000005"},
+	} {
+		if _, err := create(e.name, uuid.MustParse(e.uuid)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		reference string
+		want      string
+		wantErr   error
+	}{
+		{
+			name:      "full uuid",
+			reference: "a1b2ffff-0000-4000-8000-000000000002",
+			want:      "a1b2ffff-0000-4000-8000-000000000002",
+		},
+		{
+			name:      "name",
+			reference: "prod",
+			want:      "a1b2c3d4-0000-4000-8000-000000000001",
+		},
+		{
+			name:      "unique prefix",
+			reference: "A1B2C",
+			want:      "a1b2c3d4-0000-4000-8000-000000000001",
+		},
+		{
+			name:      "name takes precedence over prefix",
+			reference: "c0",
+			want:      "d0000000-0000-4000-8000-000000000005",
+		},
+		{
+			name:      "ambiguous prefix",
+			reference: "a1b2",
+			wantErr:   errors.New("environment reference a1b2 is ambiguous \\(matches: a1b2c3d4-0000-4000-8000-000000000001, a1b2ffff-0000-4000-8000-000000000002\\)"),
+		},
+		{
+			name:      "ambiguous name",
+			reference: "dup",
+			wantErr:   errors.New("environment reference dup is ambiguous"),
+		},
+		{
+			name:      "no match",
+			reference: "dev",
+			wantErr:   errors.New("no environment matches dev"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Find(tt.reference)
+			if isWrongResult(t, err, tt.wantErr) {
+				return
+			}
+			if err == nil && got.Uuid.String() != tt.want {
+				t.Errorf("got = %s, want %s", got.Uuid, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsedStorage(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "storage")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+	remoteDirectory, err := ioutil.TempDir(util.UsedConfigurationDirectory, "remote-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	usedStorage = storage.NewFilesystem(remoteDirectory)
+	defer func() { usedStorage = nil }()
+
+	e, err := create("e1", uuid.MustParse("0c4d5e6f-1a2b-4c3d-8e9f-a0b1c2d3e4f5"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path.Join(remoteDirectory, e.Uuid.String(), util.DefaultEnvironmentConfigFileName)); err != nil {
+		t.Errorf("config not found in storage: %v", err)
+	}
+	if _, err := os.Stat(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String(), util.DefaultEnvironmentConfigFileName)); !os.IsNotExist(err) {
+		t.Errorf("config unexpectedly written to local environment directory")
+	}
+
+	//local directory is created on first use of environment kept in storage
+	err = os.RemoveAll(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	all, err := GetAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 1 || all[0].Uuid != e.Uuid || all[0].Name != e.Name {
+		t.Errorf("GetAll() got = %v, want [%v]", all, e)
+	}
+	if _, err := os.Stat(path.Join(util.UsedEnvironmentDirectory, e.Uuid.String())); err != nil {
+		t.Errorf("local environment directory not created: %v", err)
+	}
+
+	err = e.Delete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Get(e.Uuid)
+	if !os.IsNotExist(err) {
+		t.Errorf("Get() after Delete() error = %v, want not exist error", err)
+	}
+}
+
+func TestGet_migration(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "migration")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	u := uuid.MustParse("8e1d2c3b-4a59-4687-9a0b-1c2d3e4f5a6b")
+	legacy := []byte(`name: e1
+uuid: 8e1d2c3b-4a59-4687-9a0b-1c2d3e4f5a6b
+installed: []
+`)
+	envDir := path.Join(util.UsedEnvironmentDirectory, u.String())
+	util.EnsureDirectory(envDir)
+	err := ioutil.WriteFile(path.Join(envDir, util.DefaultEnvironmentConfigFileName), legacy, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Version != "v1" || e.Kind != KindEnvironment || e.Name != "e1" {
+		t.Errorf("got = %+v", e)
+	}
+	backup, err := ioutil.ReadFile(path.Join(envDir, util.DefaultEnvironmentConfigFileName+".v0.bak"))
+	if err != nil || !bytes.Equal(backup, legacy) {
+		t.Errorf("expected backup of legacy config, got %s, error %v", backup, err)
+	}
+	migrated, err := ioutil.ReadFile(path.Join(envDir, util.DefaultEnvironmentConfigFileName))
+	if err != nil || !bytes.HasPrefix(migrated, []byte("version: v1\nkind: Environment\n")) {
+		t.Errorf("expected config to be migrated in place, got %s, error %v", migrated, err)
+	}
+}
+
+func TestEnvironment_JSON(t *testing.T) {
+	//field names are documented output contract of informational commands
+	e := &Environment{
+		Version: "v1",
+		Kind:    KindEnvironment,
+		Name:    "e1",
+		Uuid:    uuid.MustParse("10d52c05-029e-4794-a790-79d6c2af40b6"),
+		Installed: []InstalledComponentVersion{
+			{
+				EnvironmentRef: uuid.MustParse("10d52c05-029e-4794-a790-79d6c2af40b6"),
+				Name:           "c1",
+				Type:           "docker",
+				Version:        "0.1.0",
+				Image:          "i1",
+				WorkDirectory:  "/w",
+				Mounts:         []string{"/m"},
+				Commands: []InstalledComponentCommand{
+					{Name: "init", Description: "d", Command: "init", Envs: map[string]string{"K": "V"}, Args: []string{}},
+				},
+			},
+		},
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"version":"v1","kind":"Environment","name":"e1","uuid":"10d52c05-029e-4794-a790-79d6c2af40b6","installed":[{"environment_ref":"10d52c05-029e-4794-a790-79d6c2af40b6","name":"c1","type":"docker","version":"0.1.0","image":"i1","workdir":"/w","mounts":["/m"],"commands":[{"name":"init","description":"d","command":"init","envs":{"K":"V"},"args":[]}]}]}`
+	if string(data) != want {
+		t.Errorf("got = %s, want %s", data, want)
+	}
+}
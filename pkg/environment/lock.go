@@ -0,0 +1,122 @@
+package environment
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/epiphany-platform/cli/pkg/util"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v2"
+)
+
+//Lock holds information about process which holds Environment lock
+type Lock struct {
+	Pid      int       `yaml:"pid"`
+	User     string    `yaml:"user"`
+	Hostname string    `yaml:"hostname"`
+	Command  string    `yaml:"command"`
+	Time     time.Time `yaml:"time"`
+}
+
+func (l *Lock) String() string {
+	return fmt.Sprintf("%s@%s (pid %d, command \"%s\") since %s", l.User, l.Hostname, l.Pid, l.Command, l.Time.Format(time.RFC3339))
+}
+
+//LockedError is returned by Environment.Lock when Environment is already locked. Lock is nil if lock file
+//cannot be read.
+type LockedError struct {
+	Name string
+	Uuid uuid.UUID
+	Lock *Lock
+}
+
+func (e *LockedError) Error() string {
+	holder := "another process"
+	if e.Lock != nil {
+		holder = e.Lock.String()
+	}
+	return fmt.Sprintf("environment %s (%s) is locked by %s; if it is not running anymore remove lock with 'e environments unlock --force'", e.Name, e.Uuid.String(), holder)
+}
+
+//lockKey returns storage key of Environment lock file
+func lockKey(u uuid.UUID) string {
+	return u.String() + "/" + util.DefaultEnvironmentLockFileName
+}
+
+//Lock acquires exclusive lock of Environment for command. It's kept in the same storage as Environment config so
+//it's respected by all users of shared storage. LockedError is returned if Environment is already locked.
+func (e *Environment) Lock(command string) error {
+	if e.Uuid == uuid.Nil {
+		return errors.New(fmt.Sprintf("unexpected UUID on Lock: %s", e.Uuid))
+	}
+	backend, err := getStorage()
+	if err != nil {
+		return err
+	}
+	l := &Lock{
+		Pid:     os.Getpid(),
+		Command: command,
+		Time:    time.Now().UTC(),
+	}
+	if u, err := user.Current(); err == nil {
+		l.User = u.Username
+	} else {
+		l.User = os.Getenv("USER")
+	}
+	if h, err := os.Hostname(); err == nil {
+		l.Hostname = h
+	}
+	data, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+	key := lockKey(e.Uuid)
+	debug("will try to create lock %s", key)
+	err = backend.Create(key, data)
+	if os.IsExist(err) {
+		held, getErr := e.GetLock()
+		if getErr != nil {
+			warnReadLock(getErr)
+		}
+		return &LockedError{Name: e.Name, Uuid: e.Uuid, Lock: held}
+	}
+	return err
+}
+
+//Unlock releases Environment lock regardless of which process holds it. It's not an error if Environment is
+//not locked.
+func (e *Environment) Unlock() error {
+	if e.Uuid == uuid.Nil {
+		return errors.New(fmt.Sprintf("unexpected UUID on Unlock: %s", e.Uuid))
+	}
+	backend, err := getStorage()
+	if err != nil {
+		return err
+	}
+	key := lockKey(e.Uuid)
+	debug("will try to remove lock %s", key)
+	return backend.Delete(key)
+}
+
+//GetLock returns current Environment lock or nil if Environment is not locked
+func (e *Environment) GetLock() (*Lock, error) {
+	backend, err := getStorage()
+	if err != nil {
+		return nil, err
+	}
+	data, err := backend.Read(lockKey(e.Uuid))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	l := &Lock{}
+	err = yaml.Unmarshal(data, l)
+	if err != nil {
+		return nil, err
+	}
+	return l, nil
+}
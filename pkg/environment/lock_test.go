@@ -0,0 +1,62 @@
+package environment
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/epiphany-platform/cli/pkg/util"
+	"github.com/google/uuid"
+)
+
+func TestEnvironment_Lock(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "lock")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	e, err := create("e1", uuid.MustParse("7d2f0c1a-5b3e-4f6a-9c8d-1e2f3a4b5c6d"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := e.GetLock()
+	if err != nil || l != nil {
+		t.Fatalf("GetLock() on unlocked environment got = %v, error %v", l, err)
+	}
+
+	err = e.Lock("e components install c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err = e.GetLock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l == nil || l.Pid != os.Getpid() || l.Command != "e components install c1" || l.Time.IsZero() {
+		t.Errorf("GetLock() got = %+v", l)
+	}
+
+	err = e.Lock("e environments run c1 apply")
+	var lockedErr *LockedError
+	if !errors.As(err, &lockedErr) {
+		t.Fatalf("second Lock() error = %v, want LockedError", err)
+	}
+	if lockedErr.Lock == nil || lockedErr.Lock.Command != "e components install c1" {
+		t.Errorf("LockedError got lock %+v", lockedErr.Lock)
+	}
+	if !strings.Contains(err.Error(), "is locked by") || !strings.Contains(err.Error(), "e environments unlock --force") {
+		t.Errorf("unexpected error message %s", err.Error())
+	}
+
+	err = e.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = e.Unlock()
+	if err != nil {
+		t.Errorf("Unlock() of unlocked environment error = %v", err)
+	}
+	err = e.Lock("e environments run c1 apply")
+	if err != nil {
+		t.Errorf("Lock() after Unlock() error = %v", err)
+	}
+}
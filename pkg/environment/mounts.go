@@ -0,0 +1,184 @@
+package environment
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/epiphany-platform/cli/pkg/storage"
+	"github.com/epiphany-platform/cli/pkg/util"
+)
+
+//sharedStorage returns storage.Backend if Environment configs are kept in storage other than local environments
+//directory (see UseStorage). Content of mounts directories is synchronized with such storage, so that state of
+//components (like terraform state) is shared as well.
+func sharedStorage() (storage.Backend, bool, error) {
+	if usedStorage == nil && (usedStorageConfig == nil || usedStorageConfig.Type == "" || usedStorageConfig.Type == storage.TypeFilesystem) {
+		return nil, false, nil
+	}
+	backend, err := getStorage()
+	if err != nil {
+		return nil, false, err
+	}
+	return backend, true, nil
+}
+
+//mountsPath returns local path of mounts directory of InstalledComponentVersion
+func (cv *InstalledComponentVersion) mountsPath() string {
+	return path.Join(
+		util.UsedEnvironmentDirectory,
+		cv.EnvironmentRef.String(),
+		cv.Name,
+		cv.Version,
+		util.DefaultComponentMountsSubdirectory,
+	)
+}
+
+//mountsKey returns storage key prefix of mounts directory of InstalledComponentVersion
+func (cv *InstalledComponentVersion) mountsKey() string {
+	return path.Join(cv.EnvironmentRef.String(), cv.Name, cv.Version, util.DefaultComponentMountsSubdirectory) + "/"
+}
+
+//pullMounts replaces content of local mounts directory with content kept in shared storage. If there is nothing in
+//storage yet (i.e. component has never run since storage was configured) local content is kept.
+func (cv *InstalledComponentVersion) pullMounts() error {
+	backend, shared, err := sharedStorage()
+	if err != nil || !shared {
+		return err
+	}
+	prefix := cv.mountsKey()
+	keys, err := listKeys(backend, prefix)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		debug("there are no mounts of %s %s in storage, will keep local ones", cv.Name, cv.Version)
+		return nil
+	}
+	mountsPath := cv.mountsPath()
+	debug("will try to pull %d files of mounts of %s %s into %s", len(keys), cv.Name, cv.Version, mountsPath)
+	err = os.RemoveAll(mountsPath)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		data, err := backend.Read(k)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(mountsPath, filepath.FromSlash(strings.TrimPrefix(k, prefix)))
+		err = os.MkdirAll(filepath.Dir(target), 0755)
+		if err != nil {
+			return err
+		}
+		err = ioutil.WriteFile(target, data, 0644)
+		if err != nil {
+			return err
+		}
+	}
+	util.EnsureDirectory(mountsPath)
+	return nil
+}
+
+//pushMounts uploads content of local mounts directory to shared storage and removes files from storage which don't
+//exist locally anymore
+func (cv *InstalledComponentVersion) pushMounts() error {
+	backend, shared, err := sharedStorage()
+	if err != nil || !shared {
+		return err
+	}
+	prefix := cv.mountsKey()
+	mountsPath := cv.mountsPath()
+	debug("will try to push mounts of %s %s from %s", cv.Name, cv.Version, mountsPath)
+	pushed := make(map[string]bool)
+	err = filepath.Walk(mountsPath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(mountsPath, p)
+		if err != nil {
+			return err
+		}
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		key := prefix + filepath.ToSlash(rel)
+		pushed[key] = true
+		return backend.Write(key, data)
+	})
+	if err != nil {
+		return err
+	}
+	keys, err := listKeys(backend, prefix)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if !pushed[k] {
+			debug("will try to delete %s removed from local mounts", k)
+			err = backend.Delete(k)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//deleteStoredMounts removes content of mounts directory of InstalledComponentVersion from shared storage
+func (cv *InstalledComponentVersion) deleteStoredMounts() error {
+	backend, shared, err := sharedStorage()
+	if err != nil || !shared {
+		return err
+	}
+	return deleteKeys(backend, cv.mountsKey())
+}
+
+//listKeys returns all keys stored under prefix (which ends with /) including nested ones
+func listKeys(backend storage.Backend, prefix string) ([]string, error) {
+	entries, err := backend.List(prefix)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var keys []string
+	for _, e := range entries {
+		if strings.HasSuffix(e, "/") {
+			nested, err := listKeys(backend, prefix+e)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, nested...)
+		} else {
+			keys = append(keys, prefix+e)
+		}
+	}
+	return keys, nil
+}
+
+//deleteKeys removes all keys stored under prefix (which ends with /)
+func deleteKeys(backend storage.Backend, prefix string) error {
+	keys, err := listKeys(backend, prefix)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		debug("will try to delete %s", k)
+		err = backend.Delete(k)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
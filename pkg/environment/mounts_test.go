@@ -0,0 +1,91 @@
+package environment
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/epiphany-platform/cli/pkg/runtime"
+	"github.com/epiphany-platform/cli/pkg/storage"
+	"github.com/epiphany-platform/cli/pkg/util"
+	"github.com/google/uuid"
+)
+
+func TestInstalledComponentVersion_sharedMounts(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "shared-mounts")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+	remoteDirectory, err := ioutil.TempDir(util.UsedConfigurationDirectory, "remote-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	usedStorage = storage.NewFilesystem(remoteDirectory)
+	defer func() { usedStorage = nil }()
+	fake := runtime.NewFake()
+	usedRuntime = fake
+	defer func() { usedRuntime = nil }()
+
+	cv := &InstalledComponentVersion{
+		EnvironmentRef: uuid.MustParse("5a0e3c1d-2b4f-4e6a-8c9d-0e1f2a3b4c5d"),
+		Name:           "c1",
+		Type:           "docker",
+		Version:        "v1",
+		Image:          "i1",
+		Mounts:         []string{"terraform"},
+		Commands:       []InstalledComponentCommand{{Name: "apply", Command: "apply"}},
+	}
+	util.EnsureDirectory(path.Join(cv.mountsPath(), "terraform"))
+	util.EnsureDirectory(path.Join(util.UsedEnvironmentDirectory, cv.EnvironmentRef.String(), cv.Name, cv.Version, util.DefaultComponentRunsSubdirectory))
+	localState := path.Join(cv.mountsPath(), "terraform", "terraform.tfstate")
+	remoteState := path.Join(remoteDirectory, cv.mountsKey(), "terraform", "terraform.tfstate")
+
+	//state written by run is pushed to storage
+	fake.RunFunc = func(_ context.Context, job runtime.Job) error {
+		return ioutil.WriteFile(localState, []byte("state 1"), 0644)
+	}
+	if err := cv.Run("apply", RunOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := ioutil.ReadFile(remoteState); err != nil || string(data) != "state 1" {
+		t.Fatalf("got remote state %q (%v), want %q", data, err, "state 1")
+	}
+
+	//state changed in storage by someone else is pulled before run
+	if err := ioutil.WriteFile(remoteState, []byte("state 2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var seen string
+	fake.RunFunc = func(_ context.Context, job runtime.Job) error {
+		data, err := ioutil.ReadFile(localState)
+		seen = string(data)
+		if err != nil {
+			return err
+		}
+		return os.Remove(localState)
+	}
+	if err := cv.Run("apply", RunOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if seen != "state 2" {
+		t.Errorf("got state %q during run, want %q", seen, "state 2")
+	}
+	//file removed by run is removed from storage
+	if _, err := os.Stat(remoteState); !os.IsNotExist(err) {
+		t.Errorf("expected remote state to be removed but got %v", err)
+	}
+
+	if err := ioutil.WriteFile(localState, []byte("state 3"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := cv.pushMounts(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cv.PurgeData(); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := listKeys(usedStorage, cv.mountsKey())
+	if err != nil || len(keys) != 0 {
+		t.Errorf("got keys %v (%v) after purge, want none", keys, err)
+	}
+}
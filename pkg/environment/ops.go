@@ -35,6 +35,20 @@ func warnNotEnvironmentDirectory(err error) {
 		Msg("does not seam like environment directory")
 }
 
+func warnUpgradeRollback(err error) {
+	logger.
+		Warn().
+		Err(err).
+		Msg("upgrade failed, will try to restore mounts of previous version")
+}
+
+func warnCloseRunLog(err error) {
+	logger.
+		Warn().
+		Err(err).
+		Msg("failed to close run log file")
+}
+
 func errFailedToWriteFile(err error) {
 	logger.
 		Panic().
@@ -48,3 +62,43 @@ func errSaveEnvironment(err error, uuid string) {
 		Err(err).
 		Msgf("wasn't able to save environment %s", uuid)
 }
+
+func warnUsingLocalImage(image string, err error) {
+	logger.
+		Warn().
+		Err(err).
+		Msgf("pulling image %s failed, will use local image", image)
+}
+
+func warnReadLock(err error) {
+	logger.
+		Warn().
+		Err(err).
+		Msg("failed to read environment lock")
+}
+
+func infoMigrated(key string, from string, to string, backup string) {
+	logger.
+		Info().
+		Msgf("migrated %s from schema version %s to %s (backup saved as %s)", key, from, to, backup)
+}
+
+func warnRecordDigest(err error, image string) {
+	logger.
+		Warn().
+		Err(err).
+		Msgf("cannot record digest of image %s, it will be run by tag", image)
+}
+
+func warnNoDigest(image string) {
+	logger.
+		Warn().
+		Msgf("image %s has no registry digest (i.e. it was imported from bundle), it will be run by tag", image)
+}
+
+func warnPushMounts(err error, name string, version string) {
+	logger.
+		Warn().
+		Err(err).
+		Msgf("cannot push mounts of %s %s to storage, they are kept only locally", name, version)
+}
@@ -0,0 +1,181 @@
+package environment
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/epiphany-platform/cli/pkg/util"
+)
+
+const (
+	runLogTimeFormat = "20060102-150405.000MST"
+	runLogExtension  = ".log"
+)
+
+//runLog is file in component runs directory collecting interleaved stdout and stderr of single run. Each line
+//is prefixed with marker of stream it comes from.
+type runLog struct {
+	file       *os.File
+	mutex      sync.Mutex
+	lastStream string
+	lineOpen   bool
+}
+
+//newRunLog creates timestamped log file for command in provided runs directory
+func newRunLog(runsPath string, command string) (*runLog, error) {
+	util.EnsureDirectory(runsPath)
+	logPath := path.Join(runsPath, fmt.Sprintf("%s-%s%s", time.Now().Format(runLogTimeFormat), command, runLogExtension))
+	debug("will try to create run log file %s", logPath)
+	file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &runLog{file: file}, nil
+}
+
+//header writes "# key: value" line into log
+func (l *runLog) header(key string, value string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.closeLine()
+	_, _ = fmt.Fprintf(l.file, "# %s: %s\n", key, value)
+}
+
+//writer returns io.Writer writing to log with lines marked as coming from stream
+func (l *runLog) writer(stream string) io.Writer {
+	return &streamWriter{log: l, stream: stream}
+}
+
+//close writes finish header and closes log file
+func (l *runLog) close(runErr error) error {
+	l.header("finished", time.Now().Format(time.RFC3339))
+	if runErr != nil {
+		l.header("error", runErr.Error())
+	}
+	return l.file.Close()
+}
+
+func (l *runLog) closeLine() {
+	if l.lineOpen {
+		_, _ = l.file.Write([]byte("\n"))
+		l.lineOpen = false
+	}
+}
+
+func (l *runLog) write(stream string, p []byte) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if l.lastStream != stream {
+		l.closeLine()
+		l.lastStream = stream
+	}
+	for len(p) > 0 {
+		if !l.lineOpen {
+			if _, err := fmt.Fprintf(l.file, "[%s] ", stream); err != nil {
+				return err
+			}
+			l.lineOpen = true
+		}
+		line := p
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			line = p[:i+1]
+			l.lineOpen = false
+		}
+		if _, err := l.file.Write(line); err != nil {
+			return err
+		}
+		p = p[len(line):]
+	}
+	return nil
+}
+
+type streamWriter struct {
+	log    *runLog
+	stream string
+}
+
+func (w *streamWriter) Write(p []byte) (int, error) {
+	if err := w.log.write(w.stream, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+//runsPath returns path to runs directory of InstalledComponentVersion
+func (cv *InstalledComponentVersion) runsPath() string {
+	return path.Join(
+		util.UsedEnvironmentDirectory,
+		cv.EnvironmentRef.String(),
+		cv.Name,
+		cv.Version,
+		util.DefaultComponentRunsSubdirectory,
+	)
+}
+
+//isPullLog checks if log file name has no command part, as pull logs written to runs directory by older versions
+func isPullLog(name string) bool {
+	_, err := time.Parse(runLogTimeFormat, strings.TrimSuffix(name, runLogExtension))
+	return err == nil
+}
+
+//GetRuns returns sorted names of log files stored in runs directory of InstalledComponentVersion
+func (cv *InstalledComponentVersion) GetRuns() ([]string, error) {
+	items, err := ioutil.ReadDir(cv.runsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var runs []string
+	for _, i := range items {
+		if !i.IsDir() && strings.HasSuffix(i.Name(), runLogExtension) && !isPullLog(i.Name()) {
+			runs = append(runs, i.Name())
+		}
+	}
+	sort.Strings(runs)
+	return runs, nil
+}
+
+//GetRunLog returns content of log file with provided name from runs directory of InstalledComponentVersion
+func (cv *InstalledComponentVersion) GetRunLog(name string) (string, error) {
+	if name != path.Base(name) || !strings.HasSuffix(name, runLogExtension) {
+		return "", errors.New(fmt.Sprintf("incorrect run name %s", name))
+	}
+	content, err := ioutil.ReadFile(path.Join(cv.runsPath(), name))
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+//LastRun returns time when the most recent run of any InstalledComponentVersion of Environment finished (or zero
+//time if there were no runs). Runs are kept locally, so only runs executed on this machine are taken into account.
+func (e *Environment) LastRun() (time.Time, error) {
+	var last time.Time
+	for _, cv := range e.Installed {
+		runs, err := cv.GetRuns()
+		if err != nil {
+			return time.Time{}, err
+		}
+		for _, r := range runs {
+			info, err := os.Stat(path.Join(cv.runsPath(), r))
+			if err != nil {
+				return time.Time{}, err
+			}
+			if info.ModTime().After(last) {
+				last = info.ModTime()
+			}
+		}
+	}
+	return last, nil
+}
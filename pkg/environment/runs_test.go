@@ -0,0 +1,140 @@
+package environment
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/epiphany-platform/cli/pkg/util"
+	"github.com/google/uuid"
+)
+
+func TestRunLog(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "run-log")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	cv := &InstalledComponentVersion{
+		EnvironmentRef: uuid.MustParse("10d52c05-029e-4794-a790-79d6c2af40b6"),
+		Name:           "c1",
+		Version:        "v1",
+	}
+	l, err := newRunLog(cv.runsPath(), "apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.header("command", "apply -auto-approve")
+	stdout, stderr := l.writer("stdout"), l.writer("stderr")
+	_, _ = stdout.Write([]byte("line 1\nline"))
+	_, _ = stdout.Write([]byte(" 2\n"))
+	_, _ = stderr.Write([]byte("error 1"))
+	_, _ = stdout.Write([]byte("line 3\n"))
+	err = l.close(errors.New("failed"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := cv.GetRuns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || !regexp.MustCompile(`^\d{8}-\d{6}\.\d{3}.*-apply\.log$`).MatchString(runs[0]) {
+		t.Fatalf("got runs %#v", runs)
+	}
+	got, err := cv.GetRunLog(runs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := regexp.MustCompile(`^# command: apply -auto-approve
+\[stdout\] line 1
+\[stdout\] line 2
+\[stderr\] error 1
+\[stdout\] line 3
+# finished: .*
+# error: failed
+$`)
+	if !want.MatchString(got) {
+		t.Errorf("got run log \n%s\n", got)
+	}
+}
+
+func TestInstalledComponentVersion_GetRuns(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "get-runs")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	cv := &InstalledComponentVersion{
+		EnvironmentRef: uuid.MustParse("10d52c05-029e-4794-a790-79d6c2af40b6"),
+		Name:           "c1",
+		Version:        "v1",
+	}
+	got, err := cv.GetRuns()
+	if err != nil || got != nil {
+		t.Errorf("expected no runs without runs directory but got %#v (%v)", got, err)
+	}
+
+	util.EnsureDirectory(path.Join(cv.runsPath(), "subdirectory"))
+	for _, f := range []string{"20200728-173415.915CEST-init.log", "20200728-173410.000CEST.log", "other.txt"} {
+		err := ioutil.WriteFile(path.Join(cv.runsPath(), f), []byte(f), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err = cv.GetRuns()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"20200728-173415.915CEST-init.log"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %#v, want %#v", got, want)
+	}
+
+	_, err = cv.GetRunLog("../../../config.yaml")
+	if isWrongResult(t, err, errors.New("incorrect run name ../../../config.yaml")) {
+		return
+	}
+}
+
+func TestEnvironment_LastRun(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory = setup(t, "last-run")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	u := uuid.MustParse("10d52c05-029e-4794-a790-79d6c2af40b6")
+	e := &Environment{
+		Uuid: u,
+		Installed: []InstalledComponentVersion{
+			{EnvironmentRef: u, Name: "c1", Version: "v1"},
+			{EnvironmentRef: u, Name: "c2", Version: "v1"},
+		},
+	}
+	got, err := e.LastRun()
+	if err != nil || !got.IsZero() {
+		t.Errorf("expected zero time without runs but got %v (%v)", got, err)
+	}
+
+	want := time.Date(2020, 7, 28, 17, 34, 17, 0, time.UTC)
+	for i, cv := range e.Installed {
+		util.EnsureDirectory(cv.runsPath())
+		p := path.Join(cv.runsPath(), "20200728-173415.915UTC-init.log")
+		err := ioutil.WriteFile(p, []byte("log"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Chtimes(p, want, want.Add(time.Duration(-i)*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	e.Installed[0].PersistLogs("pull logs")
+	got, err = e.LastRun()
+	if err != nil || !got.Equal(want) {
+		t.Errorf("got = %v (%v), want %v", got, err, want)
+	}
+	pulls, err := ioutil.ReadDir(path.Join(util.UsedEnvironmentDirectory, u.String(), "c1", "v1", util.DefaultComponentPullsSubdirectory))
+	if err != nil || len(pulls) != 1 {
+		t.Errorf("expected one pull log in pulls directory but got %v (%v)", pulls, err)
+	}
+}
@@ -0,0 +1,91 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatYAML     = "yaml"
+	FormatTemplate = "template"
+)
+
+//Validate checks if format is one of supported output formats. Template format is provided as template=<template>.
+func Validate(format string) error {
+	switch name, argument := split(format); {
+	case argument == "" && (name == FormatText || name == FormatJSON || name == FormatYAML):
+		return nil
+	case name == FormatTemplate:
+		_, err := parseTemplate(format)
+		return err
+	}
+	return errors.New(fmt.Sprintf("unknown output format %s (available: %s, %s, %s, %s=<template>)", format, FormatText, FormatJSON, FormatYAML, FormatTemplate))
+}
+
+//Write renders v to w in format. Text format prints text as it is. JSON and YAML formats use json and yaml field
+//names of v. Template format executes Go template on JSON representation of v, so template uses the same field
+//names as JSON format (like {{.name}}).
+func Write(w io.Writer, format string, v interface{}, text string) error {
+	if err := Validate(format); err != nil {
+		return err
+	}
+	debug("will try to write output in %s format", format)
+	name, _ := split(format)
+	switch name {
+	case FormatJSON:
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case FormatYAML:
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case FormatTemplate:
+		t, err := parseTemplate(format)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var generic interface{}
+		err = json.Unmarshal(data, &generic)
+		if err != nil {
+			return err
+		}
+		return t.Execute(w, generic)
+	}
+	_, err := io.WriteString(w, text)
+	return err
+}
+
+//split format into its name and argument (template=<template> has argument)
+func split(format string) (string, string) {
+	if i := strings.Index(format, "="); i >= 0 {
+		return format[:i], format[i+1:]
+	}
+	return format, ""
+}
+
+func parseTemplate(format string) (*template.Template, error) {
+	_, text := split(format)
+	if text == "" {
+		return nil, errors.New(fmt.Sprintf("empty template in output format %s (use %s=<template>)", format, FormatTemplate))
+	}
+	return template.New("output").Option("missingkey=error").Parse(text)
+}
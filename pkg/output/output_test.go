@@ -0,0 +1,99 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type item struct {
+	Name   string            `yaml:"name" json:"name"`
+	Labels map[string]string `yaml:"labels" json:"labels"`
+	Tags   []string          `yaml:"tags" json:"tags"`
+}
+
+func TestWrite(t *testing.T) {
+	v := &item{
+		Name:   "c1",
+		Labels: map[string]string{"team": "a"},
+		Tags:   []string{"x", "y"},
+	}
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "text",
+			format: FormatText,
+			want:   "text representation\n",
+		},
+		{
+			name:   "json",
+			format: FormatJSON,
+			want: `{
+  "name": "c1",
+  "labels": {
+    "team": "a"
+  },
+  "tags": [
+    "x",
+    "y"
+  ]
+}
+`,
+		},
+		{
+			name:   "yaml",
+			format: FormatYAML,
+			want: `name: c1
+labels:
+  team: a
+tags:
+- x
+- "y"
+`,
+		},
+		{
+			name:   "template",
+			format: `template={{.name}} {{.labels.team}}{{range .tags}} {{.}}{{end}}`,
+			want:   "c1 a x y",
+		},
+		{
+			name:    "template with missing key",
+			format:  `template={{.missing}}`,
+			wantErr: errors.New(`template: output:1:2: executing "output" at <.missing>: map has no entry for key "missing"`),
+		},
+		{
+			name:    "empty template",
+			format:  "template",
+			wantErr: errors.New("empty template in output format template (use template=<template>)"),
+		},
+		{
+			name:    "unknown",
+			format:  "xml",
+			wantErr: errors.New("unknown output format xml (available: text, json, yaml, template=<template>)"),
+		},
+		{
+			name:    "argument of not template format",
+			format:  "json=x",
+			wantErr: errors.New("unknown output format json=x (available: text, json, yaml, template=<template>)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			err := Write(&b, tt.format, v, "text representation\n")
+			if err != nil || tt.wantErr != nil {
+				if err == nil || tt.wantErr == nil || err.Error() != tt.wantErr.Error() {
+					t.Errorf("got error %v, want error %v", err, tt.wantErr)
+				}
+				return
+			}
+			if b.String() != tt.want {
+				t.Errorf("got = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
@@ -34,6 +34,40 @@ func PromptForString(label string) (string, error) {
 	return result, nil
 }
 
+func PromptForPassword(label string) (string, error) {
+	prompt := promptui.Prompt{
+		Label: label,
+		Mask:  '*',
+	}
+
+	result, err := prompt.Run()
+
+	if err != nil {
+		return "", err
+	}
+
+	return result, nil
+}
+
+//PromptForConfirmation returns true if user confirmed question with y
+func PromptForConfirmation(label string) (bool, error) {
+	prompt := promptui.Prompt{
+		Label:     label,
+		IsConfirm: true,
+	}
+
+	_, err := prompt.Run()
+
+	if err == promptui.ErrAbort {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 //TODO fix it not to call config and environments here
 func PromptForEnvironmentSelect(label string) (uuid.UUID, error) {
 	config, err := configuration.GetConfig()
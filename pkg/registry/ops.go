@@ -0,0 +1,29 @@
+package registry
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	logger zerolog.Logger
+)
+
+func init() {
+	logger = log.With().
+		Str("package", "registry").
+		Logger()
+}
+
+func debug(format string, v ...interface{}) {
+	logger.
+		Debug().
+		Msgf(format, v...)
+}
+
+func warnHelperNotFound(err error, helper string) {
+	logger.
+		Warn().
+		Err(err).
+		Msgf("credential helper %s is not installed, will try without it", helper)
+}
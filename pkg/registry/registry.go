@@ -0,0 +1,292 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/epiphany-platform/cli/pkg/runtime"
+	"github.com/epiphany-platform/cli/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	DefaultServer = "docker.io"
+
+	dockerHubIndexServer = "https://index.docker.io/v1/"
+	kindRegistries       = "Registries"
+	tokenUsername        = "<token>"
+)
+
+//Credentials struct holds information required to authenticate in registry
+type Credentials struct {
+	Server        string `yaml:"server"`
+	Username      string `yaml:"username,omitempty"`
+	Password      string `yaml:"password,omitempty"`
+	IdentityToken string `yaml:"identity_token,omitempty"`
+}
+
+//registries struct is content of registries file stored in configuration directory
+type registries struct {
+	Version     string        `yaml:"version"`
+	Kind        string        `yaml:"kind"`
+	Credentials []Credentials `yaml:"registries"`
+}
+
+//dockerConfig struct is subset of ~/.docker/config.json used to find credentials
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth          string `json:"auth"`
+		IdentityToken string `json:"identitytoken"`
+	} `json:"auths"`
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+//Login stores credentials for server in registries file in configuration directory
+func Login(credentials Credentials) error {
+	if credentials.Server == "" {
+		return errors.New("empty registry server")
+	}
+	credentials.Server = normalizeServer(credentials.Server)
+	r, err := load()
+	if err != nil {
+		return err
+	}
+	updated := false
+	for i, c := range r.Credentials {
+		if c.Server == credentials.Server {
+			r.Credentials[i] = credentials
+			updated = true
+		}
+	}
+	if !updated {
+		r.Credentials = append(r.Credentials, credentials)
+	}
+	sort.Slice(r.Credentials, func(i, j int) bool {
+		return r.Credentials[i].Server < r.Credentials[j].Server
+	})
+	return r.save()
+}
+
+//Logout removes credentials for server from registries file in configuration directory
+func Logout(server string) error {
+	server = normalizeServer(server)
+	r, err := load()
+	if err != nil {
+		return err
+	}
+	var remaining []Credentials
+	for _, c := range r.Credentials {
+		if c.Server != server {
+			remaining = append(remaining, c)
+		}
+	}
+	if len(remaining) == len(r.Credentials) {
+		return errors.New(fmt.Sprintf("not logged in to %s", server))
+	}
+	r.Credentials = remaining
+	return r.save()
+}
+
+//Resolve finds credentials for registry hosting provided image. Credentials stored with Login take precedence
+//over ~/.docker/config.json (including credential helpers). It returns nil if no credentials are found.
+func Resolve(image string) (*Credentials, error) {
+	server := ServerFromImage(image)
+	debug("will try to resolve credentials for server %s", server)
+	r, err := load()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range r.Credentials {
+		if c.Server == server {
+			debug("found credentials for %s in registries file", server)
+			result := c
+			return &result, nil
+		}
+	}
+	return resolveFromDockerConfig(server)
+}
+
+//Auth resolves credentials for image (see Resolve) and converts them to runtime.Auth. It returns nil if no
+//credentials are found.
+func Auth(image string) (*runtime.Auth, error) {
+	credentials, err := Resolve(image)
+	if err != nil || credentials == nil {
+		return nil, err
+	}
+	debug("will use credentials for registry %s", credentials.Server)
+	return &runtime.Auth{
+		ServerAddress: credentials.Server,
+		Username:      credentials.Username,
+		Password:      credentials.Password,
+		IdentityToken: credentials.IdentityToken,
+	}, nil
+}
+
+//ServerFromImage returns registry server of image reference (docker.io for images without registry part)
+func ServerFromImage(image string) string {
+	i := strings.Index(image, "/")
+	if i < 0 {
+		return DefaultServer
+	}
+	first := image[:i]
+	if !strings.ContainsAny(first, ".:") && first != "localhost" {
+		return DefaultServer
+	}
+	return normalizeServer(first)
+}
+
+//normalizeServer strips scheme and path from server address and maps Docker Hub aliases to DefaultServer
+func normalizeServer(server string) string {
+	server = strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+	if i := strings.Index(server, "/"); i >= 0 {
+		server = server[:i]
+	}
+	switch server {
+	case "index.docker.io", "registry-1.docker.io", "registry.hub.docker.com":
+		return DefaultServer
+	}
+	return server
+}
+
+func registriesFilePath() string {
+	return path.Join(util.UsedConfigurationDirectory, util.DefaultRegistriesFileName)
+}
+
+func load() (*registries, error) {
+	r := &registries{
+		Version: "v1",
+		Kind:    kindRegistries,
+	}
+	p := registriesFilePath()
+	debug("will try to load registries file %s", p)
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return nil, err
+	}
+	err = yaml.Unmarshal(data, r)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *registries) save() error {
+	data, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	p := registriesFilePath()
+	debug("will try to write registries file %s", p)
+	return ioutil.WriteFile(p, data, 0600)
+}
+
+func dockerConfigFilePath() string {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return path.Join(dir, "config.json")
+	}
+	return path.Join(util.GetHomeDirectory(), ".docker", "config.json")
+}
+
+//resolveFromDockerConfig finds credentials in docker config file either using credential helper or auths section
+func resolveFromDockerConfig(server string) (*Credentials, error) {
+	p := dockerConfigFilePath()
+	debug("will try to find credentials for %s in %s", server, p)
+	data, err := ioutil.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	config := &dockerConfig{}
+	err = json.Unmarshal(data, config)
+	if err != nil {
+		return nil, err
+	}
+
+	helper := config.CredsStore
+	for s, h := range config.CredHelpers {
+		if normalizeServer(s) == server {
+			helper = h
+		}
+	}
+	if helper != "" {
+		c, err := resolveFromHelper(helper, server)
+		if err != nil || c != nil {
+			return c, err
+		}
+	}
+
+	for s, a := range config.Auths {
+		if normalizeServer(s) != server {
+			continue
+		}
+		c := &Credentials{Server: server, IdentityToken: a.IdentityToken}
+		if a.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(a.Auth)
+			if err != nil {
+				return nil, err
+			}
+			parts := strings.SplitN(string(decoded), ":", 2)
+			if len(parts) != 2 {
+				return nil, errors.New(fmt.Sprintf("incorrect auth for %s in %s", s, p))
+			}
+			c.Username, c.Password = parts[0], parts[1]
+		}
+		return c, nil
+	}
+	return nil, nil
+}
+
+//resolveFromHelper calls docker-credential-<helper> get. It returns nil if helper doesn't know server or if helper
+//is not installed (i.e. docker config was copied from another machine).
+func resolveFromHelper(helper string, server string) (*Credentials, error) {
+	debug("will try to get credentials for %s with helper %s", server, helper)
+	var candidates []string
+	if server == DefaultServer {
+		candidates = append(candidates, dockerHubIndexServer)
+	}
+	candidates = append(candidates, server, "https://"+server)
+	for _, s := range candidates {
+		cmd := exec.Command("docker-credential-"+helper, "get")
+		cmd.Stdin = strings.NewReader(s)
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout, cmd.Stderr = &stdout, &stderr
+		if err := cmd.Run(); err != nil {
+			if errors.Is(err, exec.ErrNotFound) {
+				warnHelperNotFound(err, helper)
+				return nil, nil
+			}
+			if _, ok := err.(*exec.ExitError); ok {
+				debug("helper %s has no credentials for %s: %s", helper, s, strings.TrimSpace(stderr.String()))
+				continue
+			}
+			return nil, err
+		}
+		response := &struct {
+			Username string
+			Secret   string
+		}{}
+		if err := json.Unmarshal(stdout.Bytes(), response); err != nil {
+			return nil, err
+		}
+		if response.Username == tokenUsername {
+			return &Credentials{Server: server, IdentityToken: response.Secret}, nil
+		}
+		return &Credentials{Server: server, Username: response.Username, Password: response.Secret}, nil
+	}
+	return nil, nil
+}
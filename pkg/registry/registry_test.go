@@ -0,0 +1,196 @@
+package registry
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/epiphany-platform/cli/pkg/util"
+	"github.com/rs/zerolog"
+)
+
+func setup(t *testing.T, suffix string) string {
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	dir, err := ioutil.TempDir("", "*-e-registry-"+suffix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	util.UsedConfigurationDirectory = dir
+	dockerConfigDirectory := path.Join(dir, "docker")
+	err = os.Mkdir(dockerConfigDirectory, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Setenv("DOCKER_CONFIG", dockerConfigDirectory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestServerFromImage(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "alpine", want: "docker.io"},
+		{image: "hashicorp/terraform:0.12.28", want: "docker.io"},
+		{image: "docker.io/hashicorp/terraform:0.12.28", want: "docker.io"},
+		{image: "index.docker.io/library/alpine", want: "docker.io"},
+		{image: "myregistry.azurecr.io/c1:0.1.0", want: "myregistry.azurecr.io"},
+		{image: "localhost:5000/c1", want: "localhost:5000"},
+		{image: "localhost/c1", want: "localhost"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			if got := ServerFromImage(tt.image); got != tt.want {
+				t.Errorf("got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginResolveLogout(t *testing.T) {
+	dir := setup(t, "login")
+	defer os.RemoveAll(dir)
+	defer os.Unsetenv("DOCKER_CONFIG")
+
+	err := Login(Credentials{Server: "https://myregistry.azurecr.io/v2/", Username: "u", Password: "p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path.Join(dir, util.DefaultRegistriesFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("got registries file mode %v, want 0600", info.Mode().Perm())
+	}
+
+	got, err := Resolve("myregistry.azurecr.io/c1:0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Credentials{Server: "myregistry.azurecr.io", Username: "u", Password: "p"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got = %#v, want %#v", got, want)
+	}
+
+	got, err = Resolve("other.azurecr.io/c1:0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != nil {
+		t.Errorf("got = %#v, want nil", got)
+	}
+
+	err = Logout("myregistry.azurecr.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = Resolve("myregistry.azurecr.io/c1:0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != nil {
+		t.Errorf("got = %#v, want nil after logout", got)
+	}
+
+	err = Logout("myregistry.azurecr.io")
+	if err == nil || err.Error() != "not logged in to myregistry.azurecr.io" {
+		t.Errorf("got error %v, want not logged in error", err)
+	}
+}
+
+func TestResolve_DockerConfig(t *testing.T) {
+	dir := setup(t, "docker-config")
+	defer os.RemoveAll(dir)
+	defer os.Unsetenv("DOCKER_CONFIG")
+
+	binDirectory := path.Join(dir, "bin")
+	err := os.Mkdir(binDirectory, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	helper := `#!/bin/sh
+read server
+if [ "$server" = "helped.io" ]; then
+  echo '{"ServerURL":"helped.io","Username":"<token>","Secret":"t0k3n"}'
+  exit 0
+fi
+echo "credentials not found in native keychain" >&2
+exit 1
+`
+	err = ioutil.WriteFile(path.Join(binDirectory, "docker-credential-test"), []byte(helper), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldPath := os.Getenv("PATH")
+	defer os.Setenv("PATH", oldPath)
+	_ = os.Setenv("PATH", binDirectory+string(os.PathListSeparator)+oldPath)
+
+	config := `{
+  "auths": {
+    "https://index.docker.io/v1/": {"auth": "aHViOnNlY3JldA=="},
+    "plain.io": {"auth": "dXNlcjpwYXNz"},
+    "fallback.io": {"auth": "dXNlcjpwYXNz"}
+  },
+  "credHelpers": {
+    "helped.io": "test",
+    "missing.io": "not-installed",
+    "fallback.io": "not-installed"
+  }
+}`
+	err = ioutil.WriteFile(path.Join(dir, "docker", "config.json"), []byte(config), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		image string
+		want  *Credentials
+	}{
+		{
+			name:  "docker hub auths",
+			image: "hashicorp/terraform:0.12.28",
+			want:  &Credentials{Server: "docker.io", Username: "hub", Password: "secret"},
+		},
+		{
+			name:  "registry auths",
+			image: "plain.io/c1",
+			want:  &Credentials{Server: "plain.io", Username: "user", Password: "pass"},
+		},
+		{
+			name:  "credential helper",
+			image: "helped.io/c1",
+			want:  &Credentials{Server: "helped.io", IdentityToken: "t0k3n"},
+		},
+		{
+			name:  "unknown registry",
+			image: "unknown.io/c1",
+		},
+		{
+			name:  "credential helper not installed",
+			image: "missing.io/c1",
+		},
+		{
+			name:  "credential helper not installed with auths",
+			image: "fallback.io/c1",
+			want:  &Credentials{Server: "fallback.io", Username: "user", Password: "pass"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.image)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	DefaultCacheTTL = 24 * time.Hour
+)
+
+var (
+	usedCacheTTL = DefaultCacheTTL
+	httpClient   = &http.Client{Timeout: 30 * time.Second}
+)
+
+//cacheMetadata is stored next to cached repository file. It allows to send conditional requests and to decide if
+//cached repository file is stale.
+type cacheMetadata struct {
+	ETag         string    `yaml:"etag,omitempty"`
+	LastModified string    `yaml:"last_modified,omitempty"`
+	FetchedAt    time.Time `yaml:"fetched_at"`
+}
+
+//UseCacheTTL sets age after which cached remote repository files are refreshed. Zero or negative ttl disables
+//automatic refresh, so cached files are refreshed only with Source.Update.
+func UseCacheTTL(ttl time.Duration) {
+	usedCacheTTL = ttl
+}
+
+//metadataPath returns path of metadata file of provided cache file
+func metadataPath(cacheFile string) string {
+	return cacheFile + ".meta"
+}
+
+//loadMetadata reads metadata of cache file. If there is no metadata file (cache created by older version) time of
+//last modification of cache file is used as FetchedAt.
+func loadMetadata(cacheFile string) *cacheMetadata {
+	meta := &cacheMetadata{}
+	data, err := ioutil.ReadFile(metadataPath(cacheFile))
+	if err == nil {
+		err = yaml.Unmarshal(data, meta)
+	}
+	if err != nil {
+		debug("cannot read metadata of %s: %v", cacheFile, err)
+		meta = &cacheMetadata{}
+		if info, err := os.Stat(cacheFile); err == nil {
+			meta.FetchedAt = info.ModTime()
+		}
+	}
+	return meta
+}
+
+//save writes metadata next to cache file
+func (m *cacheMetadata) save(cacheFile string) error {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(metadataPath(cacheFile), data, 0644)
+}
+
+//isStale checks if cache fetched at FetchedAt is older than ttl
+func (m *cacheMetadata) isStale(ttl time.Duration) bool {
+	if ttl <= 0 {
+		return false
+	}
+	return time.Since(m.FetchedAt) > ttl
+}
+
+//The downloadAndPersistRepositoryV1 method retrieves file from provided url, unmarshalls and validates it as V1 and
+//writes file and its metadata to cache file. If metadata of existing cache file is provided, conditional request is
+//sent and if server responds that file was not modified, cached V1 is returned. Returned bool informs if cache file
+//was changed. Responses other than 200 OK (or 304 Not Modified for conditional request) and invalid repositories are
+//rejected, so existing cache file is never overwritten with them. Detached signature (url with .sig suffix) is
+//downloaded and cached as well, and if there are trusted keys (see UseTrustedKeys) repository not signed with one of
+//them is rejected too.
+func downloadAndPersistRepositoryV1(url string, cacheFile string, meta *cacheMetadata) (*V1, bool, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if meta != nil {
+		if meta.ETag != "" {
+			request.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			request.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+	res, err := httpClient.Do(request)
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotModified && meta != nil {
+		debug("repository %s was not modified", url)
+		repository, err := loadRepository(cacheFile)
+		if err != nil {
+			return nil, false, err
+		}
+		meta.FetchedAt = time.Now()
+		return repository, false, meta.save(cacheFile)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, false, errors.New(fmt.Sprintf("downloading repository %s failed: %s", url, res.Status))
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	repository := &V1{}
+	err = yaml.Unmarshal(body, repository)
+	if err != nil {
+		return nil, false, errors.New(fmt.Sprintf("downloaded repository %s is not correct yaml: %v", url, err))
+	}
+	err = repository.Validate()
+	if err != nil {
+		return nil, false, err
+	}
+	signature, err := downloadSignature(url)
+	if err != nil {
+		if VerificationRequired() {
+			return nil, false, err
+		}
+		//signatures are optional without trusted keys, so repository is cached without it
+		debug("will cache repository %s without signature: %v", url, err)
+		signature = nil
+	}
+	if VerificationRequired() {
+		name, err := verifySignature(body, signature)
+		if err != nil {
+			return nil, false, errors.New(fmt.Sprintf("verification of repository %s failed: %v", url, err))
+		}
+		debug("repository %s is signed with trusted key %s", url, name)
+	}
+	err = os.MkdirAll(path.Dir(cacheFile), 0755)
+	if err != nil {
+		return nil, false, err
+	}
+	err = ioutil.WriteFile(cacheFile, body, 0644)
+	if err != nil {
+		return nil, false, err
+	}
+	if signature != nil {
+		err = ioutil.WriteFile(signaturePath(cacheFile), signature, 0644)
+	} else {
+		err = os.Remove(signaturePath(cacheFile))
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	newMeta := &cacheMetadata{
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+		FetchedAt:    time.Now(),
+	}
+	return repository, true, newMeta.save(cacheFile)
+}
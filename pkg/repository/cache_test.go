@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/epiphany-platform/cli/pkg/util"
+)
+
+func repositoryYaml(image string) string {
+	return `version: v1
+kind: Repository
+components:
+- name: c1
+  type: docker
+  versions:
+  - version: 0.1.0
+    latest: true
+    image: ` + image + `
+`
+}
+
+func TestSource_load_cache(t *testing.T) {
+	tests := []struct {
+		name          string
+		cached        string
+		cachedMeta    *cacheMetadata
+		ttl           time.Duration
+		status        int
+		served        string
+		update        bool
+		wantImage     string
+		wantModified  bool
+		wantRequests  int
+		wantCondition string
+		wantErr       bool
+	}{
+		{
+			name:         "no cache",
+			ttl:          time.Hour,
+			status:       http.StatusOK,
+			served:       repositoryYaml("remote/c1:0.1.0"),
+			wantImage:    "remote/c1:0.1.0",
+			wantRequests: 1,
+		},
+		{
+			name:         "fresh cache",
+			cached:       repositoryYaml("cached/c1:0.1.0"),
+			cachedMeta:   &cacheMetadata{ETag: `"1"`, FetchedAt: time.Now()},
+			ttl:          time.Hour,
+			status:       http.StatusOK,
+			served:       repositoryYaml("remote/c1:0.1.0"),
+			wantImage:    "cached/c1:0.1.0",
+			wantRequests: 0,
+		},
+		{
+			name:         "stale cache but refresh disabled",
+			cached:       repositoryYaml("cached/c1:0.1.0"),
+			cachedMeta:   &cacheMetadata{ETag: `"1"`, FetchedAt: time.Now().Add(-48 * time.Hour)},
+			ttl:          0,
+			status:       http.StatusOK,
+			served:       repositoryYaml("remote/c1:0.1.0"),
+			wantImage:    "cached/c1:0.1.0",
+			wantRequests: 0,
+		},
+		{
+			name:          "stale cache not modified",
+			cached:        repositoryYaml("cached/c1:0.1.0"),
+			cachedMeta:    &cacheMetadata{ETag: `"1"`, FetchedAt: time.Now().Add(-2 * time.Hour)},
+			ttl:           time.Hour,
+			status:        http.StatusNotModified,
+			wantImage:     "cached/c1:0.1.0",
+			wantRequests:  1,
+			wantCondition: `"1"`,
+		},
+		{
+			name:          "stale cache modified",
+			cached:        repositoryYaml("cached/c1:0.1.0"),
+			cachedMeta:    &cacheMetadata{ETag: `"1"`, FetchedAt: time.Now().Add(-2 * time.Hour)},
+			ttl:           time.Hour,
+			status:        http.StatusOK,
+			served:        repositoryYaml("remote/c1:0.1.0"),
+			wantImage:     "remote/c1:0.1.0",
+			wantRequests:  1,
+			wantCondition: `"1"`,
+		},
+		{
+			name:          "stale cache and server error",
+			cached:        repositoryYaml("cached/c1:0.1.0"),
+			cachedMeta:    &cacheMetadata{ETag: `"1"`, FetchedAt: time.Now().Add(-2 * time.Hour)},
+			ttl:           time.Hour,
+			status:        http.StatusInternalServerError,
+			served:        "<html>error</html>",
+			wantImage:     "cached/c1:0.1.0",
+			wantRequests:  1,
+			wantCondition: `"1"`,
+		},
+		{
+			name:          "stale cache and invalid document",
+			cached:        repositoryYaml("cached/c1:0.1.0"),
+			cachedMeta:    &cacheMetadata{ETag: `"1"`, FetchedAt: time.Now().Add(-2 * time.Hour)},
+			ttl:           time.Hour,
+			status:        http.StatusOK,
+			served:        "<html>login</html>",
+			wantImage:     "cached/c1:0.1.0",
+			wantRequests:  1,
+			wantCondition: `"1"`,
+		},
+		{
+			name:         "cache without metadata uses file modification time",
+			cached:       repositoryYaml("cached/c1:0.1.0"),
+			ttl:          time.Hour,
+			status:       http.StatusOK,
+			served:       repositoryYaml("remote/c1:0.1.0"),
+			wantImage:    "cached/c1:0.1.0",
+			wantRequests: 0,
+		},
+		{
+			name:          "update of fresh cache",
+			cached:        repositoryYaml("cached/c1:0.1.0"),
+			cachedMeta:    &cacheMetadata{ETag: `"1"`, FetchedAt: time.Now()},
+			ttl:           time.Hour,
+			status:        http.StatusOK,
+			served:        repositoryYaml("remote/c1:0.1.0"),
+			update:        true,
+			wantImage:     "remote/c1:0.1.0",
+			wantModified:  true,
+			wantRequests:  1,
+			wantCondition: `"1"`,
+		},
+		{
+			name:          "update not modified",
+			cached:        repositoryYaml("cached/c1:0.1.0"),
+			cachedMeta:    &cacheMetadata{ETag: `"1"`, FetchedAt: time.Now()},
+			ttl:           time.Hour,
+			status:        http.StatusNotModified,
+			update:        true,
+			wantImage:     "cached/c1:0.1.0",
+			wantRequests:  1,
+			wantCondition: `"1"`,
+		},
+		{
+			name:          "update with server error",
+			cached:        repositoryYaml("cached/c1:0.1.0"),
+			cachedMeta:    &cacheMetadata{ETag: `"1"`, FetchedAt: time.Now()},
+			ttl:           time.Hour,
+			status:        http.StatusNotFound,
+			served:        "<html>not found</html>",
+			update:        true,
+			wantImage:     "cached/c1:0.1.0",
+			wantRequests:  1,
+			wantCondition: `"1"`,
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedRepositoryFile = setup(t, "cache")
+			defer os.RemoveAll(util.UsedConfigurationDirectory)
+			defer func() { util.UsedRepositoryFile = "" }()
+			defer UseCacheTTL(DefaultCacheTTL)
+			UseCacheTTL(tt.ttl)
+
+			requests := 0
+			condition := ""
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, signatureSuffix) {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				requests++
+				condition = r.Header.Get("If-None-Match")
+				w.Header().Set("ETag", `"2"`)
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.served))
+			}))
+			defer server.Close()
+
+			s := Source{Name: "remote", Url: server.URL + "/v1.yaml"}
+			if tt.cached != "" {
+				if err := os.MkdirAll(path.Join(util.UsedConfigurationDirectory, util.DefaultRepositoriesSubdirectory), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := ioutil.WriteFile(s.CachePath(), []byte(tt.cached), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.cachedMeta != nil {
+				if err := tt.cachedMeta.save(s.CachePath()); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if tt.update {
+				modified, err := s.Update()
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+				}
+				if modified != tt.wantModified {
+					t.Errorf("got modified %t, want %t", modified, tt.wantModified)
+				}
+			}
+			v, err := s.load()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := v.Components[0].Versions[0].Image; got != tt.wantImage {
+				t.Errorf("got image %s, want %s", got, tt.wantImage)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("got %d requests, want %d", requests, tt.wantRequests)
+			}
+			if condition != tt.wantCondition {
+				t.Errorf("got If-None-Match %s, want %s", condition, tt.wantCondition)
+			}
+			refreshed := tt.status == http.StatusNotModified || tt.wantImage == "remote/c1:0.1.0"
+			if tt.wantRequests > 0 && refreshed {
+				meta := loadMetadata(s.CachePath())
+				if time.Since(meta.FetchedAt) > time.Minute {
+					t.Errorf("metadata was not refreshed, fetched at %s", meta.FetchedAt)
+				}
+				if tt.status == http.StatusOK && meta.ETag != `"2"` {
+					t.Errorf("got ETag %s, want %s", meta.ETag, `"2"`)
+				}
+			}
+		})
+	}
+}
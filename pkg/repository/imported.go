@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	"github.com/epiphany-platform/cli/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	kindRepository = "Repository"
+)
+
+//AddImported adds Component with single ComponentVersion (i.e. taken from imported bundle) to imported repository
+//file stored in configuration directory. Components from that file are merged into repository by GetRepository, so
+//they can be installed without access to default repository location.
+func AddImported(c Component) error {
+	if len(c.Versions) != 1 {
+		return errors.New(fmt.Sprintf("expected exactly one version of imported component %s but found %d", c.Name, len(c.Versions)))
+	}
+	imported, err := loadImported()
+	if err != nil {
+		return err
+	}
+	added := c.Versions[0]
+	var existing *Component
+	for i := range imported.Components {
+		if imported.Components[i].Name == c.Name {
+			existing = &imported.Components[i]
+		}
+	}
+	if existing == nil {
+		added.IsLatest = true
+		imported.Components = append(imported.Components, Component{
+			Name:     c.Name,
+			Type:     c.Type,
+			Versions: []ComponentVersion{added},
+		})
+	} else {
+		var versions []ComponentVersion
+		for _, v := range existing.Versions {
+			if v.Version != added.Version {
+				versions = append(versions, v)
+			}
+		}
+		existing.Type = c.Type
+		existing.Versions = append(versions, added)
+		markLatest(existing)
+	}
+	data, err := yaml.Marshal(imported)
+	if err != nil {
+		return err
+	}
+	debug("will try to write imported repository file %s", importedRepositoryFilePath())
+	return ioutil.WriteFile(importedRepositoryFilePath(), data, 0644)
+}
+
+//markLatest marks the highest semantic version of component as IsLatest. If there are no semantic versions the last
+//one is marked.
+func markLatest(c *Component) {
+	latest := len(c.Versions) - 1
+	var best *semanticVersion
+	for i, v := range c.Versions {
+		c.Versions[i].IsLatest = false
+		sv, err := parseVersion(v.Version)
+		if err != nil {
+			continue
+		}
+		if best == nil || sv.compare(best) > 0 {
+			best = sv
+			latest = i
+		}
+	}
+	if latest >= 0 {
+		c.Versions[latest].IsLatest = true
+	}
+}
+
+//merge adds components and versions from other repository which are not present in v. Versions added to already
+//existing components are never marked IsLatest, so v decides which version is the latest.
+func (v *V1) merge(other *V1) {
+	for _, oc := range other.Components {
+		index := -1
+		for i, c := range v.Components {
+			if c.Name == oc.Name {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			v.Components = append(v.Components, oc)
+			continue
+		}
+		for _, ov := range oc.Versions {
+			found := false
+			for _, cv := range v.Components[index].Versions {
+				if cv.Version == ov.Version {
+					found = true
+				}
+			}
+			if !found {
+				ov.IsLatest = false
+				v.Components[index].Versions = append(v.Components[index].Versions, ov)
+			}
+		}
+	}
+}
+
+func importedRepositoryFilePath() string {
+	return path.Join(util.UsedConfigurationDirectory, util.DefaultImportedRepositoryFileName)
+}
+
+//loadImported loads imported repository file or returns empty repository if there is no such file. All loaded
+//versions are marked Imported, so they are recognized after being merged into components of other repositories.
+func loadImported() (*V1, error) {
+	repo, err := loadRepository(importedRepositoryFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &V1{
+				Version: "v1",
+				Kind:    kindRepository,
+			}, nil
+		}
+		return nil, err
+	}
+	for i := range repo.Components {
+		for j := range repo.Components[i].Versions {
+			repo.Components[i].Versions[j].Imported = true
+		}
+	}
+	return repo, nil
+}
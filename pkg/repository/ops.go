@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -27,3 +29,24 @@ func errGetRepository(err error) {
 		Err(err).
 		Msg("get repository failed")
 }
+
+func warnUsingImportedOnly(err error) {
+	logger.
+		Warn().
+		Err(err).
+		Msg("cannot get repository, will use only imported components")
+}
+
+func warnSkippingRepository(err error, name string) {
+	logger.
+		Warn().
+		Err(err).
+		Msgf("cannot get repository %s, will skip it", name)
+}
+
+func warnStaleRepository(err error, name string, fetchedAt time.Time) {
+	logger.
+		Warn().
+		Err(err).
+		Msgf("cannot refresh repository %s, will use cached one fetched at %s", name, fetchedAt.Format(time.RFC3339))
+}
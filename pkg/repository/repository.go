@@ -4,21 +4,20 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"os"
+	"strings"
 
-	"github.com/epiphany-platform/cli/pkg/util"
 	"gopkg.in/yaml.v2"
 )
 
 //ComponentCommand struct contains information about specific command provided by component to be executed
 type ComponentCommand struct {
-	Name        string            `yaml:"name"`
-	Description string            `yaml:"description"`
-	Command     string            `yaml:"command"`
-	Envs        map[string]string `yaml:"envs"`
-	Args        []string          `yaml:"args"`
+	Name        string            `yaml:"name" json:"name"`
+	Description string            `yaml:"description" json:"description"`
+	Command     string            `yaml:"command" json:"command"`
+	Envs        map[string]string `yaml:"envs" json:"envs"`
+	Args        []string          `yaml:"args" json:"args"`
+	Interactive bool              `yaml:"interactive" json:"interactive"`
 }
 
 //The String method is used to pretty-print ComponentCommand struct
@@ -28,12 +27,13 @@ func (cc *ComponentCommand) String() string {
 
 //ComponentVersion struct contains information about version of component available to be installed
 type ComponentVersion struct {
-	Version       string             `yaml:"version"`
-	IsLatest      bool               `yaml:"latest"`
-	Image         string             `yaml:"image"`
-	WorkDirectory string             `yaml:"workdir"`
-	Mounts        []string           `yaml:"mounts"`
-	Commands      []ComponentCommand `yaml:"commands"`
+	Version       string             `yaml:"version" json:"version"`
+	IsLatest      bool               `yaml:"latest" json:"latest"`
+	Image         string             `yaml:"image" json:"image"`
+	WorkDirectory string             `yaml:"workdir" json:"workdir"`
+	Mounts        []string           `yaml:"mounts" json:"mounts"`
+	Commands      []ComponentCommand `yaml:"commands" json:"commands"`
+	Imported      bool               `yaml:"-" json:"-"`
 }
 
 //The String method is used to pretty-print ComponentVersion struct
@@ -48,9 +48,10 @@ func (cv *ComponentVersion) String() string {
 
 //Component struct is main element in repository identifying component and gathering all versions of it
 type Component struct {
-	Name     string             `yaml:"name"`
-	Type     string             `yaml:"type"`
-	Versions []ComponentVersion `yaml:"versions"`
+	Name       string             `yaml:"name" json:"name"`
+	Type       string             `yaml:"type" json:"type"`
+	Versions   []ComponentVersion `yaml:"versions" json:"versions"`
+	Repository string             `yaml:"repository,omitempty" json:"repository,omitempty"`
 }
 
 //The String method is used to pretty-print Component struct
@@ -76,8 +77,9 @@ func (c *Component) JustLatestVersion() (*Component, error) {
 		}
 	}
 	result := &Component{
-		Name: c.Name,
-		Type: c.Type,
+		Name:       c.Name,
+		Type:       c.Type,
+		Repository: c.Repository,
 	}
 	for _, v := range c.Versions {
 		if v.IsLatest {
@@ -90,77 +92,141 @@ func (c *Component) JustLatestVersion() (*Component, error) {
 	return result, nil
 }
 
+//The JustVersion method returns Component with just one ComponentVersion matching provided constraint. Constraint
+//can be exact version (like 0.1.0) or semver range (like ~0.1, ^1.2 or >=1.0.0). When there are multiple versions
+//matching range the highest one is selected. Empty constraint means version marked as IsLatest.
+func (c *Component) JustVersion(constraint string) (*Component, error) {
+	if constraint == "" {
+		return c.JustLatestVersion()
+	}
+	if len(c.Versions) < 1 {
+		return nil, errors.New("no versions in component")
+	}
+	result := &Component{
+		Name:       c.Name,
+		Type:       c.Type,
+		Repository: c.Repository,
+	}
+	for _, v := range c.Versions {
+		if v.Version == constraint {
+			result.Versions = append(result.Versions, v)
+			return result, nil
+		}
+	}
+	vc, err := parseConstraint(constraint)
+	if err != nil {
+		return nil, err
+	}
+	var best *semanticVersion
+	var available []string
+	for _, v := range c.Versions {
+		available = append(available, v.Version)
+		sv, err := parseVersion(v.Version)
+		if err != nil {
+			debug("skipping not semantic version %s of component %s", v.Version, c.Name)
+			continue
+		}
+		if vc.matches(sv) && (best == nil || sv.compare(best) > 0) {
+			best = sv
+			result.Versions = []ComponentVersion{v}
+		}
+	}
+	if best == nil {
+		return nil, errors.New(fmt.Sprintf("no version of component %s matches %s (available: %s)", c.Name, constraint, strings.Join(available, ", ")))
+	}
+	return result, nil
+}
+
 //V1 struct is entrypoint repository for version 1 of used repository structure
 type V1 struct {
-	Version    string      `yaml:"version"`
-	Kind       string      `yaml:"kind"`
-	Components []Component `yaml:"components"`
+	Version    string      `yaml:"version" json:"version"`
+	Kind       string      `yaml:"kind" json:"kind"`
+	Components []Component `yaml:"components" json:"components"`
 }
 
-//The GetComponentByName method gets first Component matching name parameter from V1 repository
+//The GetComponentByName method gets first Component matching name parameter from V1 repository. Name can be
+//qualified with repository name (like repo/component) to select Component from specific repository.
 func (v V1) GetComponentByName(name string) (*Component, error) {
+	repository := ""
+	if i := strings.Index(name, "/"); i >= 0 {
+		repository, name = name[:i], name[i+1:]
+	}
 	for _, c := range v.Components {
-		if c.Name == name {
+		if c.Name == name && (repository == "" || c.Repository == repository) {
 			return &c, nil
 		}
 	}
 	return nil, errors.New("unknown component")
 }
 
-//The ComponentsString method is used to pretty-print V1 repository Component list
+//The QualifiedName method returns Component name prefixed with name of repository it comes from
+func (c *Component) QualifiedName() string {
+	if c.Repository == "" {
+		return c.Name
+	}
+	return c.Repository + "/" + c.Name
+}
+
+//The ComponentsString method is used to pretty-print V1 repository Component list. Names of components present in
+//multiple repositories are qualified with repository name.
 func (v V1) ComponentsString() string {
+	count := make(map[string]int)
+	for _, c := range v.Components {
+		count[c.Name]++
+	}
 	var b bytes.Buffer
 	for _, c := range v.Components {
+		name := c.Name
+		if count[c.Name] > 1 {
+			name = c.QualifiedName()
+		}
 		for _, v := range c.Versions {
-			b.WriteString(fmt.Sprintf("Component: %s:%s\n", c.Name, v.Version))
+			b.WriteString(fmt.Sprintf("Component: %s:%s\n", name, v.Version))
 		}
 	}
 	return b.String()
 }
 
-//The GetRepository method checks if there is already cached repository file and returns V1 struct. If there is no
-//cache file it will try to download it from default location, persist it to cache file and return V1 as well.
+//The GetRepository method merges V1 repositories of all configured sources (see UseSources) into single V1, in order
+//of sources priority. Remote sources are loaded from cache files or downloaded and persisted to cache files if there
+//is no cache. Components imported from bundles (see AddImported) are merged into returned V1 as well. Sources which
+//cannot be loaded are skipped with warning unless there is nothing else to return.
 func GetRepository() *V1 {
 	debug("will try to get repo")
-	repo, err := loadRepository(util.UsedRepositoryFile)
+	imported, err := loadImported()
 	if err != nil {
-		debug("error while loading local repo: %#v", err)
-		debug("will try to download repo")
-		repo, err = downloadAndPersistRepositoryV1(fmt.Sprintf("%s/%s/%s/%s", util.GithubUrl, util.DefaultRepository, util.DefaultRepositoryBranch, util.DefaultV1RepositoryFileName))
+		errGetRepository(err)
+	}
+	repo := &V1{
+		Version: imported.Version,
+		Kind:    imported.Kind,
+	}
+	var lastErr error
+	loaded := 0
+	for _, s := range Sources() {
+		r, err := s.load()
 		if err != nil {
-			errGetRepository(err)
+			warnSkippingRepository(err, s.Name)
+			lastErr = err
+			continue
 		}
+		loaded++
+		for _, c := range r.Components {
+			c.Repository = s.Name
+			repo.Components = append(repo.Components, c)
+		}
+	}
+	if loaded == 0 {
+		if len(imported.Components) == 0 {
+			errGetRepository(lastErr)
+		}
+		warnUsingImportedOnly(lastErr)
 	}
+	repo.merge(imported)
 	debug("will return repo")
 	return repo
 }
 
-//The downloadAndPersistRepositoryV1 method retrieves file from provided url, unmarshalls it to V1 and writes file to
-//util.UsedRepositoryFile. Eventually it also returns obtained V1 struct.
-func downloadAndPersistRepositoryV1(url string) (*V1, error) {
-	res, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	if res.Body != nil {
-		defer res.Body.Close()
-	}
-	body, err := ioutil.ReadAll(res.Body)
-	if err != nil {
-		return nil, err
-	}
-	repository := &V1{}
-	err = yaml.Unmarshal(body, repository)
-	if err != nil {
-		return nil, err
-	}
-	err = ioutil.WriteFile(util.UsedRepositoryFile, body, 0644)
-	if err != nil {
-		return nil, err
-	}
-	return repository, nil
-}
-
 //The loadRepository method loads V1 from provided file path
 func loadRepository(repoFilePath string) (*V1, error) {
 	repo := &V1{}
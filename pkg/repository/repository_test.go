@@ -161,6 +161,122 @@ func TestComponent_JustLatestVersion(t *testing.T) {
 	}
 }
 
+func TestComponent_JustVersion(t *testing.T) {
+	mock := &Component{
+		Name:       "c",
+		Type:       "t",
+		Repository: "r",
+		Versions: []ComponentVersion{
+			{Version: "0.1.0"},
+			{Version: "0.1.2"},
+			{Version: "0.2.0-rc.1"},
+			{Version: "1.2.0"},
+			{Version: "1.3.1", IsLatest: true},
+			{Version: "2.0.0"},
+			{Version: "custom"},
+		},
+	}
+	tests := []struct {
+		name       string
+		constraint string
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "empty means latest",
+			constraint: "",
+			want:       "1.3.1",
+		},
+		{
+			name:       "exact",
+			constraint: "0.1.0",
+			want:       "0.1.0",
+		},
+		{
+			name:       "exact with v prefix",
+			constraint: "v0.1.0",
+			want:       "0.1.0",
+		},
+		{
+			name:       "exact not semantic",
+			constraint: "custom",
+			want:       "custom",
+		},
+		{
+			name:       "exact pre-release",
+			constraint: "0.2.0-rc.1",
+			want:       "0.2.0-rc.1",
+		},
+		{
+			name:       "partial",
+			constraint: "1",
+			want:       "1.3.1",
+		},
+		{
+			name:       "tilde",
+			constraint: "~0.1",
+			want:       "0.1.2",
+		},
+		{
+			name:       "tilde with patch",
+			constraint: "~1.2.0",
+			want:       "1.2.0",
+		},
+		{
+			name:       "caret",
+			constraint: "^1.2",
+			want:       "1.3.1",
+		},
+		{
+			name:       "caret below 1.0.0",
+			constraint: "^0.1.1",
+			want:       "0.1.2",
+		},
+		{
+			name:       "greater or equal",
+			constraint: ">=1.0.0",
+			want:       "2.0.0",
+		},
+		{
+			name:       "lower than",
+			constraint: "<1.3.1",
+			want:       "1.2.0",
+		},
+		{
+			name:       "pre-release range",
+			constraint: ">=0.2.0-rc.0",
+			want:       "2.0.0",
+		},
+		{
+			name:       "no match",
+			constraint: "^3.0",
+			wantErr:    errors.New("no version of component c matches \\^3.0 \\(available: 0.1.0, 0.1.2, 0.2.0-rc.1, 1.2.0, 1.3.1, 2.0.0, custom\\)"),
+		},
+		{
+			name:       "incorrect constraint",
+			constraint: "~a.b",
+			wantErr:    errors.New("incorrect version constraint ~a.b"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mock.JustVersion(tt.constraint)
+			if isWrongResult(t, err, tt.wantErr) {
+				return
+			}
+			if err != nil {
+				return
+			}
+			if len(got.Versions) != 1 || got.Versions[0].Version != tt.want {
+				t.Errorf("got = %#v, want version %s", got.Versions, tt.want)
+			}
+			if got.Repository != mock.Repository {
+				t.Errorf("got repository %q, want %q", got.Repository, mock.Repository)
+			}
+		})
+	}
+}
+
 func TestV1_GetComponentByName(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -381,3 +497,198 @@ components:
 		})
 	}
 }
+
+func TestAddImported(t *testing.T) {
+	var repoFile string
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, repoFile = setup(t, "add-imported")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+
+	for _, v := range []string{"0.2.0", "0.10.0", "0.3.0"} {
+		err := AddImported(Component{
+			Name:     "c1",
+			Type:     "docker",
+			Versions: []ComponentVersion{{Version: v, Image: "i1:" + v}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := AddImported(Component{Name: "c1", Type: "docker"})
+	if isWrongResult(t, err, errors.New("expected exactly one version of imported component c1 but found 0")) {
+		return
+	}
+
+	_ = ioutil.WriteFile(repoFile, []byte(`version: v1
+kind: Repository
+components:
+- name: c1
+  type: docker
+  versions:
+  - version: 0.1.0
+    latest: true
+    image: i1:0.1.0
+`), 0644)
+	defer func() { util.UsedRepositoryFile = "" }()
+	util.UsedRepositoryFile = repoFile
+
+	imported, err := loadImported()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var latest []string
+	for _, v := range imported.Components[0].Versions {
+		if v.IsLatest {
+			latest = append(latest, v.Version)
+		}
+	}
+	if !reflect.DeepEqual(latest, []string{"0.10.0"}) {
+		t.Errorf("got latest imported versions %#v, want 0.10.0", latest)
+	}
+
+	c, err := GetRepository().GetComponentByName("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Versions) != 4 {
+		t.Errorf("got %d versions, want 4", len(c.Versions))
+	}
+	got, err := c.JustLatestVersion()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Versions[0].Version != "0.1.0" {
+		t.Errorf("got latest version %s, want version from repository", got.Versions[0].Version)
+	}
+}
+
+func TestGetRepository_sources(t *testing.T) {
+	var repoFile string
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, repoFile = setup(t, "sources")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+	defer func() { util.UsedRepositoryFile = "" }()
+	util.UsedRepositoryFile = repoFile
+	defer UseSources(nil)
+
+	publicFile := path.Join(util.UsedConfigurationDirectory, "public.yaml")
+	_ = ioutil.WriteFile(publicFile, []byte(`version: v1
+kind: Repository
+components:
+- name: c1
+  type: docker
+  versions:
+  - version: 0.1.0
+    latest: true
+    image: public/c1:0.1.0
+- name: c2
+  type: docker
+  versions:
+  - version: 0.1.0
+    latest: true
+    image: public/c2:0.1.0
+`), 0644)
+	internalFile := path.Join(util.UsedConfigurationDirectory, "internal.yaml")
+	_ = ioutil.WriteFile(internalFile, []byte(`version: v1
+kind: Repository
+components:
+- name: c1
+  type: docker
+  versions:
+  - version: 0.2.0
+    latest: true
+    image: internal/c1:0.2.0
+`), 0644)
+	UseSources([]Source{
+		{Name: "public", Url: publicFile},
+		{Name: "internal", Url: "file://" + internalFile, Priority: 10},
+		{Name: "broken", Url: path.Join(util.UsedConfigurationDirectory, "missing.yaml"), Priority: 5},
+	})
+
+	repo := GetRepository()
+	tests := []struct {
+		name      string
+		reference string
+		wantImage string
+		wantErr   error
+	}{
+		{
+			name:      "collision resolved by priority",
+			reference: "c1",
+			wantImage: "internal/c1:0.2.0",
+		},
+		{
+			name:      "qualified name",
+			reference: "public/c1",
+			wantImage: "public/c1:0.1.0",
+		},
+		{
+			name:      "qualified name of the highest priority component",
+			reference: "internal/c1",
+			wantImage: "internal/c1:0.2.0",
+		},
+		{
+			name:      "unique name",
+			reference: "c2",
+			wantImage: "public/c2:0.1.0",
+		},
+		{
+			name:      "wrong repository",
+			reference: "internal/c2",
+			wantErr:   errors.New("unknown component"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := repo.GetComponentByName(tt.reference)
+			if isWrongResult(t, err, tt.wantErr) || err != nil {
+				return
+			}
+			if c.Versions[0].Image != tt.wantImage {
+				t.Errorf("got image %s, want %s", c.Versions[0].Image, tt.wantImage)
+			}
+		})
+	}
+
+	want := "Component: internal/c1:0.2.0\nComponent: public/c1:0.1.0\nComponent: c2:0.1.0\n"
+	if got := repo.ComponentsString(); got != want {
+		t.Errorf("got components \n%s\nwant \n%s", got, want)
+	}
+}
+
+func TestValidateSource(t *testing.T) {
+	configured := []Source{{Name: "public", Url: "https://example.com/v1.yaml"}}
+	tests := []struct {
+		name    string
+		source  Source
+		wantErr error
+	}{
+		{
+			name:   "correct",
+			source: Source{Name: "internal", Url: "/repo/v1.yaml"},
+		},
+		{
+			name:    "empty name",
+			source:  Source{Url: "/repo/v1.yaml"},
+			wantErr: errors.New("empty repository name"),
+		},
+		{
+			name:    "incorrect name",
+			source:  Source{Name: "a/b", Url: "/repo/v1.yaml"},
+			wantErr: errors.New(regexp.QuoteMeta("incorrect repository name a/b (it cannot contain any of / \\ : @ and space)")),
+		},
+		{
+			name:    "empty url",
+			source:  Source{Name: "internal"},
+			wantErr: errors.New("empty url of repository internal"),
+		},
+		{
+			name:    "duplicate",
+			source:  Source{Name: "public", Url: "/repo/v1.yaml"},
+			wantErr: errors.New("repository public already exists"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isWrongResult(t, ValidateSource(tt.source, configured), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	signatureSuffix = ".sig"
+)
+
+var (
+	usedTrustedKeys  []trustedKey
+	skipVerification bool
+)
+
+//TrustedKey is named ed25519 public key (base64 encoded) used to verify detached signatures of repository files
+type TrustedKey struct {
+	Name      string `yaml:"name" json:"name"`
+	PublicKey string `yaml:"public-key" json:"public-key"`
+}
+
+//trustedKey is parsed TrustedKey
+type trustedKey struct {
+	name string
+	key  ed25519.PublicKey
+}
+
+//UseTrustedKeys selects keys used to verify repositories. If there is at least one trusted key all repositories
+//have to be signed with one of them. Empty keys disable verification.
+func UseTrustedKeys(keys []TrustedKey) error {
+	var parsed []trustedKey
+	for _, k := range keys {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(k.PublicKey))
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return errors.New(fmt.Sprintf("incorrect trusted key %s (expected base64 encoded %d bytes ed25519 public key)", k.Name, ed25519.PublicKeySize))
+		}
+		parsed = append(parsed, trustedKey{name: k.Name, key: key})
+	}
+	usedTrustedKeys = parsed
+	return nil
+}
+
+//SkipVerification disables verification of repositories even if there are trusted keys configured
+func SkipVerification() {
+	skipVerification = true
+}
+
+//VerificationRequired checks if there are trusted keys configured and verification is not skipped
+func VerificationRequired() bool {
+	return len(usedTrustedKeys) > 0 && !skipVerification
+}
+
+//signaturePath returns path of detached signature of provided repository file
+func signaturePath(file string) string {
+	return file + signatureSuffix
+}
+
+//verifySignature checks base64 encoded detached ed25519 signature of data against trusted keys and returns name of
+//key which made signature
+func verifySignature(data []byte, signature []byte) (string, error) {
+	if len(signature) == 0 {
+		return "", errors.New("repository is not signed")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return "", errors.New("incorrect repository signature")
+	}
+	for _, k := range usedTrustedKeys {
+		if ed25519.Verify(k.key, data, sig) {
+			return k.name, nil
+		}
+	}
+	return "", errors.New("repository signature does not match any trusted key")
+}
+
+//downloadSignature retrieves detached signature of repository file from url. Nil is returned if there is no
+//signature.
+func downloadSignature(url string) ([]byte, error) {
+	res, err := httpClient.Get(url + signatureSuffix)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		debug("there is no signature of repository %s", url)
+		return nil, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.New(fmt.Sprintf("downloading signature of repository %s failed: %s", url, res.Status))
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+//Verify checks signature of Source repository file (cache file for remote sources) against trusted keys. It does
+//nothing if there are no trusted keys.
+func (s Source) Verify() error {
+	if !VerificationRequired() {
+		return nil
+	}
+	file := strings.TrimPrefix(s.Url, "file://")
+	if s.IsRemote() {
+		file = s.CachePath()
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	signature, err := ioutil.ReadFile(signaturePath(file))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	name, err := verifySignature(data, signature)
+	if err != nil {
+		return errors.New(fmt.Sprintf("verification of repository %s failed: %v", s.Name, err))
+	}
+	debug("repository %s is signed with trusted key %s", s.Name, name)
+	return nil
+}
+
+//VerifyComponent checks signature of repository Component comes from. Versions imported from bundles cannot be
+//verified, so they are refused if verification is required.
+func VerifyComponent(c *Component) error {
+	if !VerificationRequired() {
+		return nil
+	}
+	for _, v := range c.Versions {
+		if v.Imported {
+			return errors.New(fmt.Sprintf("version %s of component %s is imported from bundle and cannot be verified", v.Version, c.Name))
+		}
+	}
+	if c.Repository == "" {
+		return errors.New(fmt.Sprintf("unknown repository of component %s", c.Name))
+	}
+	for _, s := range Sources() {
+		if s.Name == c.Repository {
+			return s.Verify()
+		}
+	}
+	return errors.New(fmt.Sprintf("unknown repository %s of component %s", c.Repository, c.Name))
+}
@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/epiphany-platform/cli/pkg/util"
+)
+
+func generateKey(t *testing.T) (TrustedKey, ed25519.PrivateKey) {
+	public, private, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return TrustedKey{Name: "test", PublicKey: base64.StdEncoding.EncodeToString(public)}, private
+}
+
+func sign(private ed25519.PrivateKey, data string) string {
+	return base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte(data))) + "\n"
+}
+
+func TestUseTrustedKeys(t *testing.T) {
+	key, _ := generateKey(t)
+	defer func() { _ = UseTrustedKeys(nil) }()
+	tests := []struct {
+		name         string
+		keys         []TrustedKey
+		wantRequired bool
+		wantErr      bool
+	}{
+		{
+			name: "no keys",
+		},
+		{
+			name:         "correct key",
+			keys:         []TrustedKey{key},
+			wantRequired: true,
+		},
+		{
+			name:    "not base64",
+			keys:    []TrustedKey{{Name: "wrong", PublicKey: "not base64!"}},
+			wantErr: true,
+		},
+		{
+			name:    "wrong length",
+			keys:    []TrustedKey{{Name: "wrong", PublicKey: base64.StdEncoding.EncodeToString([]byte("short"))}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = UseTrustedKeys(nil)
+			err := UseTrustedKeys(tt.keys)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if got := VerificationRequired(); got != tt.wantRequired {
+				t.Errorf("got verification required %t, want %t", got, tt.wantRequired)
+			}
+		})
+	}
+}
+
+func TestSource_signature(t *testing.T) {
+	key, private := generateKey(t)
+	_, otherPrivate := generateKey(t)
+	data := repositoryYaml("remote/c1:0.1.0")
+	tests := []struct {
+		name            string
+		keys            []TrustedKey
+		signature       string
+		signatureStatus int
+		wantErr         bool
+		wantCached      bool
+	}{
+		{
+			name:       "unsigned without trusted keys",
+			wantCached: true,
+		},
+		{
+			name:       "signed without trusted keys",
+			signature:  sign(otherPrivate, data),
+			wantCached: true,
+		},
+		{
+			name:            "signature not accessible without trusted keys",
+			signatureStatus: http.StatusForbidden,
+			wantCached:      true,
+		},
+		{
+			name:       "signed with trusted key",
+			keys:       []TrustedKey{key},
+			signature:  sign(private, data),
+			wantCached: true,
+		},
+		{
+			name:      "signed with untrusted key",
+			keys:      []TrustedKey{key},
+			signature: sign(otherPrivate, data),
+			wantErr:   true,
+		},
+		{
+			name:    "unsigned with trusted key",
+			keys:    []TrustedKey{key},
+			wantErr: true,
+		},
+		{
+			name:            "signature not accessible with trusted key",
+			keys:            []TrustedKey{key},
+			signatureStatus: http.StatusForbidden,
+			wantErr:         true,
+		},
+		{
+			name:      "incorrect signature",
+			keys:      []TrustedKey{key},
+			signature: "not a signature",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, util.UsedRepositoryFile = setup(t, "signature")
+			defer os.RemoveAll(util.UsedConfigurationDirectory)
+			defer func() { util.UsedRepositoryFile = "" }()
+			defer func() { _ = UseTrustedKeys(nil) }()
+			if err := UseTrustedKeys(tt.keys); err != nil {
+				t.Fatal(err)
+			}
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if strings.HasSuffix(r.URL.Path, signatureSuffix) {
+					if tt.signatureStatus != 0 {
+						w.WriteHeader(tt.signatureStatus)
+						return
+					}
+					if tt.signature == "" {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					_, _ = w.Write([]byte(tt.signature))
+					return
+				}
+				_, _ = w.Write([]byte(data))
+			}))
+			defer server.Close()
+
+			s := Source{Name: "remote", Url: server.URL + "/v1.yaml"}
+			_, err := s.load()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			_, err = os.Stat(s.CachePath())
+			if cached := err == nil; cached != tt.wantCached {
+				t.Fatalf("got cached %t, want %t", cached, tt.wantCached)
+			}
+			if !tt.wantCached {
+				return
+			}
+			UseSources([]Source{s})
+			defer UseSources(nil)
+			err = VerifyComponent(&Component{Name: "c1", Repository: s.Name})
+			if err != nil {
+				t.Errorf("got verification error %v", err)
+			}
+
+			err = ioutil.WriteFile(s.CachePath(), []byte(repositoryYaml("tampered/c1:0.1.0")), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = s.Verify()
+			if (err != nil) != VerificationRequired() {
+				t.Errorf("got verification error of tampered cache %v, want error %t", err, VerificationRequired())
+			}
+		})
+	}
+}
+
+func TestSource_Verify_local(t *testing.T) {
+	key, private := generateKey(t)
+	directory, err := ioutil.TempDir(os.TempDir(), "*-e-repository-local-signature")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+	defer func() { _ = UseTrustedKeys(nil) }()
+	if err := UseTrustedKeys([]TrustedKey{key}); err != nil {
+		t.Fatal(err)
+	}
+	data := repositoryYaml("local/c1:0.1.0")
+	file := path.Join(directory, "v1.yaml")
+	if err := ioutil.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := Source{Name: "local", Url: "file://" + file}
+
+	if err := s.Verify(); err == nil {
+		t.Errorf("unsigned local repository was verified")
+	}
+	if err := ioutil.WriteFile(signaturePath(file), []byte(sign(private, data)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Verify(); err != nil {
+		t.Errorf("got verification error %v", err)
+	}
+	if err := VerifyComponent(&Component{Name: "imported", Versions: []ComponentVersion{{Version: "0.1.0", Imported: true}}}); err == nil {
+		t.Errorf("imported component was verified")
+	}
+}
+
+func TestVerifyComponent_version(t *testing.T) {
+	util.UsedConfigFile, util.UsedConfigurationDirectory, util.UsedEnvironmentDirectory, _ = setup(t, "verify-component")
+	defer os.RemoveAll(util.UsedConfigurationDirectory)
+	key, private := generateKey(t)
+	defer func() { _ = UseTrustedKeys(nil) }()
+	if err := UseTrustedKeys([]TrustedKey{key}); err != nil {
+		t.Fatal(err)
+	}
+	data := repositoryYaml("local/c1:0.1.0")
+	file := path.Join(util.UsedConfigurationDirectory, "local.yaml")
+	if err := ioutil.WriteFile(file, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer UseSources(nil)
+	UseSources([]Source{{Name: "local", Url: "file://" + file}})
+
+	tc, err := GetRepository().GetComponentByName("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, constraint := range []string{"", "0.1.0"} {
+		c, err := tc.JustVersion(constraint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Repository != "local" {
+			t.Errorf("got repository %q of version %q, want %q", c.Repository, constraint, "local")
+		}
+		if err := VerifyComponent(c); err == nil {
+			t.Errorf("component of version %q from unsigned repository was verified", constraint)
+		}
+	}
+
+	if err := ioutil.WriteFile(signaturePath(file), []byte(sign(private, data)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := tc.JustVersion("0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyComponent(c); err != nil {
+		t.Errorf("got verification error %v", err)
+	}
+
+	//version imported from bundle is merged into signed component but cannot be verified
+	err = AddImported(Component{
+		Name:     "c1",
+		Type:     "docker",
+		Versions: []ComponentVersion{{Version: "6.6.6", Image: "evil/image:1"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc, err = GetRepository().GetComponentByName("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err = tc.JustVersion("6.6.6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyComponent(c); err == nil {
+		t.Errorf("imported version of signed component was verified")
+	}
+	c, err = tc.JustVersion("0.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyComponent(c); err != nil {
+		t.Errorf("got verification error of signed version %v", err)
+	}
+}
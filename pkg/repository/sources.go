@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/epiphany-platform/cli/pkg/util"
+)
+
+const (
+	DefaultSourceName = "default"
+)
+
+var (
+	usedSources []Source
+)
+
+//Source is location of V1 repository file. Url is either http(s) URL (repository file is downloaded and cached in
+//configuration directory) or local file path. Components from sources with higher Priority take precedence over
+//components with the same name from sources with lower Priority.
+type Source struct {
+	Name     string `yaml:"name" json:"name"`
+	Url      string `yaml:"url" json:"url"`
+	Priority int    `yaml:"priority" json:"priority"`
+}
+
+//DefaultSource returns Source of default public repository used when no sources are configured
+func DefaultSource() Source {
+	return Source{
+		Name: DefaultSourceName,
+		Url:  fmt.Sprintf("%s/%s/%s/%s", util.GithubUrl, util.DefaultRepository, util.DefaultRepositoryBranch, util.DefaultV1RepositoryFileName),
+	}
+}
+
+//UseSources selects sources merged by GetRepository. Empty sources mean DefaultSource only.
+func UseSources(sources []Source) {
+	usedSources = sources
+}
+
+//Sources returns sources used by GetRepository ordered from the highest priority. Sources with the same priority
+//keep configured order.
+func Sources() []Source {
+	sources := []Source{DefaultSource()}
+	if len(usedSources) > 0 {
+		sources = append([]Source{}, usedSources...)
+	}
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].Priority > sources[j].Priority
+	})
+	return sources
+}
+
+//ValidateSource checks if Source can be added to already configured sources
+func ValidateSource(s Source, configured []Source) error {
+	if s.Name == "" {
+		return errors.New("empty repository name")
+	}
+	if strings.ContainsAny(s.Name, "/\\:@ ") {
+		return errors.New(fmt.Sprintf("incorrect repository name %s (it cannot contain any of / \\ : @ and space)", s.Name))
+	}
+	if s.Url == "" {
+		return errors.New(fmt.Sprintf("empty url of repository %s", s.Name))
+	}
+	for _, c := range configured {
+		if c.Name == s.Name {
+			return errors.New(fmt.Sprintf("repository %s already exists", s.Name))
+		}
+	}
+	return nil
+}
+
+//IsRemote checks if Source is downloaded over http(s)
+func (s Source) IsRemote() bool {
+	return strings.HasPrefix(s.Url, "http://") || strings.HasPrefix(s.Url, "https://")
+}
+
+//CachePath returns path of file where remote Source is cached. Default source uses util.UsedRepositoryFile.
+func (s Source) CachePath() string {
+	if s.Name == DefaultSourceName {
+		return util.UsedRepositoryFile
+	}
+	return path.Join(path.Dir(util.UsedRepositoryFile), util.DefaultRepositoriesSubdirectory, s.Name+".yaml")
+}
+
+//load V1 from Source. Remote sources are loaded from cache file or downloaded if there is no cache. Cache file older
+//than TTL (see UseCacheTTL) is refreshed, and if refresh fails stale cache is used with warning.
+func (s Source) load() (*V1, error) {
+	if !s.IsRemote() {
+		debug("will try to load repository %s from file %s", s.Name, s.Url)
+		return loadRepository(strings.TrimPrefix(s.Url, "file://"))
+	}
+	cacheFile := s.CachePath()
+	repo, err := loadRepository(cacheFile)
+	if err != nil {
+		debug("error while loading cached repository %s: %#v", s.Name, err)
+		debug("will try to download repository %s", s.Name)
+		repo, _, err = downloadAndPersistRepositoryV1(s.Url, cacheFile, nil)
+		return repo, err
+	}
+	meta := loadMetadata(cacheFile)
+	if !meta.isStale(usedCacheTTL) {
+		return repo, nil
+	}
+	debug("cached repository %s fetched at %s is stale, will try to refresh it", s.Name, meta.FetchedAt)
+	fresh, _, err := downloadAndPersistRepositoryV1(s.Url, cacheFile, meta)
+	if err != nil {
+		warnStaleRepository(err, s.Name, meta.FetchedAt)
+		return repo, nil
+	}
+	return fresh, nil
+}
+
+//Update refreshes cache of remote Source regardless of its age. Conditional request is sent if there is valid cache
+//already. Returned bool informs if cached repository file was changed. Local sources are never changed.
+func (s Source) Update() (bool, error) {
+	if !s.IsRemote() {
+		return false, nil
+	}
+	cacheFile := s.CachePath()
+	var meta *cacheMetadata
+	if _, err := loadRepository(cacheFile); err == nil {
+		meta = loadMetadata(cacheFile)
+	}
+	_, modified, err := downloadAndPersistRepositoryV1(s.Url, cacheFile, meta)
+	return modified, err
+}
+
+//RemoveCache removes cache file of Source with its metadata and signature
+func (s Source) RemoveCache() error {
+	for _, f := range []string{s.CachePath(), metadataPath(s.CachePath()), signaturePath(s.CachePath())} {
+		err := os.Remove(f)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
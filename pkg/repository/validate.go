@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	versionV1 = "v1"
+)
+
+var (
+	//knownComponentTypes lists values of Component Type supported by e
+	knownComponentTypes = []string{"docker"}
+)
+
+//ValidationError lists all problems found in V1 repository document
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid repository: %s", strings.Join(e.Problems, "; "))
+}
+
+//Validate checks if V1 is correct repository document: it has to have known version and kind, component names have
+//to be unique, each component has to have known type and exactly one latest version, versions have to be unique
+//semantic versions with image, and commands have to have names. All found problems are returned as ValidationError.
+func (v V1) Validate() error {
+	var problems []string
+	add := func(format string, a ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, a...))
+	}
+	if v.Version != versionV1 {
+		add("unknown version %q (expected %s)", v.Version, versionV1)
+	}
+	if v.Kind != kindRepository {
+		add("unknown kind %q (expected %s)", v.Kind, kindRepository)
+	}
+	components := make(map[string]bool)
+	for i, c := range v.Components {
+		name := c.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+			add("component %s has empty name", name)
+		} else if components[name] {
+			add("component %s is defined more than once", name)
+		}
+		components[c.Name] = true
+		if !isKnownComponentType(c.Type) {
+			add("component %s has unknown type %q (expected one of %s)", name, c.Type, strings.Join(knownComponentTypes, ", "))
+		}
+		if len(c.Versions) == 0 {
+			add("component %s has no versions", name)
+		}
+		latest := 0
+		versions := make(map[string]bool)
+		for j, cv := range c.Versions {
+			version := cv.Version
+			if version == "" {
+				version = fmt.Sprintf("#%d", j+1)
+				add("component %s version %s has empty version", name, version)
+			} else if _, err := parseVersion(version); err != nil {
+				add("component %s version %s is not semantic version", name, version)
+			} else if versions[version] {
+				add("component %s version %s is defined more than once", name, version)
+			}
+			versions[cv.Version] = true
+			if cv.IsLatest {
+				latest++
+			}
+			if cv.Image == "" {
+				add("component %s version %s has empty image", name, version)
+			}
+			for k, cc := range cv.Commands {
+				if cc.Name == "" {
+					add("component %s version %s command #%d has empty name", name, version, k+1)
+				}
+			}
+		}
+		if len(c.Versions) > 0 && latest != 1 {
+			add("component %s has %d versions marked as latest (expected exactly one)", name, latest)
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+//Lint parses repository document strictly (unknown fields are reported) and validates it
+func Lint(data []byte) error {
+	v := &V1{}
+	err := yaml.UnmarshalStrict(data, v)
+	if err != nil {
+		return err
+	}
+	return v.Validate()
+}
+
+func isKnownComponentType(t string) bool {
+	for _, k := range knownComponentTypes {
+		if k == t {
+			return true
+		}
+	}
+	return false
+}
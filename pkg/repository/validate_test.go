@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestV1_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		repository   V1
+		wantProblems []string
+	}{
+		{
+			name: "valid",
+			repository: V1{
+				Version: "v1",
+				Kind:    "Repository",
+				Components: []Component{
+					{
+						Name: "c1",
+						Type: "docker",
+						Versions: []ComponentVersion{
+							{Version: "0.1.0", Image: "c1:0.1.0"},
+							{Version: "0.2.0", IsLatest: true, Image: "c1:0.2.0", Commands: []ComponentCommand{{Name: "init"}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "unknown version and kind",
+			repository: V1{
+				Version: "v2",
+				Kind:    "Something",
+			},
+			wantProblems: []string{
+				`unknown version "v2" (expected v1)`,
+				`unknown kind "Something" (expected Repository)`,
+			},
+		},
+		{
+			name: "incorrect components",
+			repository: V1{
+				Version: "v1",
+				Kind:    "Repository",
+				Components: []Component{
+					{
+						Name: "c1",
+						Type: "docker",
+						Versions: []ComponentVersion{
+							{Version: "0.1.0", IsLatest: true, Image: "c1:0.1.0"},
+						},
+					},
+					{
+						Name: "c1",
+						Type: "helm",
+						Versions: []ComponentVersion{
+							{Version: "0.1.0", IsLatest: true, Image: "c1:0.1.0"},
+							{Version: "0.1.0", IsLatest: true, Commands: []ComponentCommand{{Name: ""}}},
+							{Version: "latest", Image: "c1:latest"},
+						},
+					},
+					{
+						Type: "docker",
+					},
+					{
+						Name: "c2",
+						Type: "docker",
+						Versions: []ComponentVersion{
+							{Image: "c2:0.1.0"},
+						},
+					},
+				},
+			},
+			wantProblems: []string{
+				"component c1 is defined more than once",
+				`component c1 has unknown type "helm" (expected one of docker)`,
+				"component c1 version 0.1.0 is defined more than once",
+				"component c1 version 0.1.0 has empty image",
+				"component c1 version 0.1.0 command #1 has empty name",
+				"component c1 version latest is not semantic version",
+				"component c1 has 2 versions marked as latest (expected exactly one)",
+				"component #3 has empty name",
+				"component #3 has no versions",
+				"component c2 version #1 has empty version",
+				"component c2 has 0 versions marked as latest (expected exactly one)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.repository.Validate()
+			if tt.wantProblems == nil {
+				if err != nil {
+					t.Fatalf("got unexpected error %v", err)
+				}
+				return
+			}
+			ve, ok := err.(*ValidationError)
+			if !ok {
+				t.Fatalf("got error %v, want ValidationError", err)
+			}
+			if !reflect.DeepEqual(ve.Problems, tt.wantProblems) {
+				t.Errorf("got problems \n%#v\nwant \n%#v", ve.Problems, tt.wantProblems)
+			}
+		})
+	}
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			data: repositoryYaml("c1:0.1.0"),
+		},
+		{
+			name:    "unknown field",
+			data:    repositoryYaml("c1:0.1.0") + "    tag: 0.1.0\n",
+			wantErr: true,
+		},
+		{
+			name:    "not yaml",
+			data:    "<html>not found</html>",
+			wantErr: true,
+		},
+		{
+			name:    "empty",
+			data:    "",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Lint([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
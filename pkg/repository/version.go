@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//semanticVersion holds parsed semver 2.0 version (build metadata is ignored)
+type semanticVersion struct {
+	major      int
+	minor      int
+	patch      int
+	prerelease []string
+}
+
+//parseVersion parses full semantic version like 1.2.3 or v1.2.3-rc.1
+func parseVersion(s string) (*semanticVersion, error) {
+	v, parts, err := parsePartialVersion(s)
+	if err != nil {
+		return nil, err
+	}
+	if parts != 3 {
+		return nil, errors.New(fmt.Sprintf("incomplete version %s", s))
+	}
+	return v, nil
+}
+
+//parsePartialVersion parses version allowing to skip minor and patch part (like 1 or 1.2). It returns number of
+//version parts found.
+func parsePartialVersion(s string) (*semanticVersion, int, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+	v := &semanticVersion{}
+	if i := strings.Index(s, "-"); i >= 0 {
+		if i == len(s)-1 {
+			return nil, 0, errors.New(fmt.Sprintf("incorrect version %s", s))
+		}
+		v.prerelease = strings.Split(s[i+1:], ".")
+		s = s[:i]
+	}
+	numbers := strings.Split(s, ".")
+	if len(numbers) < 1 || len(numbers) > 3 {
+		return nil, 0, errors.New(fmt.Sprintf("incorrect version %s", s))
+	}
+	for i, n := range numbers {
+		value, err := strconv.Atoi(n)
+		if err != nil || value < 0 {
+			return nil, 0, errors.New(fmt.Sprintf("incorrect version %s", s))
+		}
+		switch i {
+		case 0:
+			v.major = value
+		case 1:
+			v.minor = value
+		case 2:
+			v.patch = value
+		}
+	}
+	if v.prerelease != nil && len(numbers) != 3 {
+		return nil, 0, errors.New(fmt.Sprintf("incorrect version %s", s))
+	}
+	return v, len(numbers), nil
+}
+
+//compare returns -1, 0 or 1 if v is lower, equal or greater than other according to semver precedence
+func (v *semanticVersion) compare(other *semanticVersion) int {
+	if d := compareInts(v.major, other.major); d != 0 {
+		return d
+	}
+	if d := compareInts(v.minor, other.minor); d != 0 {
+		return d
+	}
+	if d := compareInts(v.patch, other.patch); d != 0 {
+		return d
+	}
+	if len(v.prerelease) == 0 && len(other.prerelease) == 0 {
+		return 0
+	}
+	if len(v.prerelease) == 0 {
+		return 1
+	}
+	if len(other.prerelease) == 0 {
+		return -1
+	}
+	for i := 0; i < len(v.prerelease) && i < len(other.prerelease); i++ {
+		a, aErr := strconv.Atoi(v.prerelease[i])
+		b, bErr := strconv.Atoi(other.prerelease[i])
+		var d int
+		switch {
+		case aErr == nil && bErr == nil:
+			d = compareInts(a, b)
+		case aErr == nil:
+			d = -1
+		case bErr == nil:
+			d = 1
+		default:
+			d = strings.Compare(v.prerelease[i], other.prerelease[i])
+		}
+		if d != 0 {
+			return d
+		}
+	}
+	return compareInts(len(v.prerelease), len(other.prerelease))
+}
+
+func compareInts(a, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+//versionConstraint is range of versions [lower, upper) with optional inclusiveness of bounds
+type versionConstraint struct {
+	lower          *semanticVersion
+	lowerInclusive bool
+	upper          *semanticVersion
+	upperInclusive bool
+	prerelease     bool
+}
+
+//parseConstraint parses constraints like 1.2.3, =1.2.3, 1.2, ~1.2, ^1.2, >=1.2.0, <2.0.0
+func parseConstraint(s string) (*versionConstraint, error) {
+	s = strings.TrimSpace(s)
+	operator := ""
+	for _, o := range []string{">=", "<=", ">", "<", "=", "~", "^"} {
+		if strings.HasPrefix(s, o) {
+			operator = o
+			s = strings.TrimSpace(strings.TrimPrefix(s, o))
+			break
+		}
+	}
+	v, parts, err := parsePartialVersion(s)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("incorrect version constraint %s%s", operator, s))
+	}
+	c := &versionConstraint{prerelease: len(v.prerelease) > 0}
+	switch operator {
+	case ">=", ">":
+		c.lower, c.lowerInclusive = v, operator == ">="
+	case "<=", "<":
+		c.upper, c.upperInclusive = v, operator == "<="
+	case "~":
+		c.lower, c.lowerInclusive = v, true
+		if parts == 1 {
+			c.upper = &semanticVersion{major: v.major + 1}
+		} else {
+			c.upper = &semanticVersion{major: v.major, minor: v.minor + 1}
+		}
+	case "^":
+		c.lower, c.lowerInclusive = v, true
+		switch {
+		case v.major > 0 || parts == 1:
+			c.upper = &semanticVersion{major: v.major + 1}
+		case v.minor > 0 || parts == 2:
+			c.upper = &semanticVersion{minor: v.minor + 1}
+		default:
+			c.upper = &semanticVersion{patch: v.patch + 1}
+		}
+	default:
+		c.lower, c.lowerInclusive = v, true
+		switch parts {
+		case 1:
+			c.upper = &semanticVersion{major: v.major + 1}
+		case 2:
+			c.upper = &semanticVersion{major: v.major, minor: v.minor + 1}
+		default:
+			c.upper, c.upperInclusive = v, true
+		}
+	}
+	return c, nil
+}
+
+//matches checks if provided version is within constraint. Pre-release versions are matched only when constraint
+//itself contains pre-release part.
+func (c *versionConstraint) matches(v *semanticVersion) bool {
+	if len(v.prerelease) > 0 && !c.prerelease {
+		return false
+	}
+	if c.lower != nil {
+		d := v.compare(c.lower)
+		if d < 0 || (d == 0 && !c.lowerInclusive) {
+			return false
+		}
+	}
+	if c.upper != nil {
+		d := v.compare(c.upper)
+		if d > 0 || (d == 0 && !c.upperInclusive) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,135 @@
+package runtime
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+const (
+	fakeArchivePrefix = "fake image archive: "
+)
+
+//Fake is in-memory Runtime recording all calls. It's intended to be used in tests.
+type Fake struct {
+	mutex sync.Mutex
+
+	//Images holds "pulled" images by name
+	Images map[string]*ImageInfo
+	//Jobs holds all jobs passed to Run
+	Jobs []Job
+	//PullErr is returned by Pull if set
+	PullErr error
+	//PullAuths holds auth passed to Pull by image name
+	PullAuths map[string]*Auth
+	//RunFunc is called by Run if set, otherwise Run writes command name to job stdout and succeeds
+	RunFunc func(ctx context.Context, job Job) error
+}
+
+//NewFake creates empty Fake runtime
+func NewFake() *Fake {
+	return &Fake{
+		Images:    make(map[string]*ImageInfo),
+		PullAuths: make(map[string]*Auth),
+	}
+}
+
+func (f *Fake) Pull(image string, auth *Auth, progress io.Writer) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.PullAuths[image] = auth
+	if f.PullErr != nil {
+		return "", f.PullErr
+	}
+	f.addImage(image, true)
+	if progress != nil {
+		_, _ = fmt.Fprintf(progress, "pulled %s\n", image)
+	}
+	return fmt.Sprintf("pulled %s\n", image), nil
+}
+
+//addImage adds image to Images if it's not there yet. Pulled images get RepoDigests entry with digest being sha256
+//of image name. Mutex has to be locked by caller.
+func (f *Fake) addImage(image string, pulled bool) {
+	info, ok := f.Images[image]
+	if !ok {
+		info = &ImageInfo{
+			ID:       fmt.Sprintf("sha256:%064x", len(f.Images)+1),
+			RepoTags: []string{image},
+		}
+		f.Images[image] = info
+	}
+	if pulled && len(info.RepoDigests) == 0 {
+		info.RepoDigests = []string{fmt.Sprintf("%s@sha256:%x", ImageRepository(image), sha256.Sum256([]byte(image)))}
+	}
+}
+
+func (f *Fake) Run(ctx context.Context, job Job) error {
+	f.mutex.Lock()
+	f.Jobs = append(f.Jobs, job)
+	runFunc := f.RunFunc
+	f.mutex.Unlock()
+	if runFunc != nil {
+		return runFunc(ctx, job)
+	}
+	if job.Stdout != nil {
+		_, _ = fmt.Fprintf(job.Stdout, "%s\n", job.Command)
+	}
+	return nil
+}
+
+func (f *Fake) Remove(image string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if _, ok := f.Images[image]; !ok {
+		return errors.New(fmt.Sprintf("no such image: %s", image))
+	}
+	delete(f.Images, image)
+	return nil
+}
+
+func (f *Fake) Inspect(image string) (*ImageInfo, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	info, ok := f.Images[image]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("no such image: %s", image))
+	}
+	result := *info
+	return &result, nil
+}
+
+//Save writes single line archive containing image name
+func (f *Fake) Save(image string, w io.Writer) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if _, ok := f.Images[image]; !ok {
+		return errors.New(fmt.Sprintf("no such image: %s", image))
+	}
+	_, err := fmt.Fprintf(w, "%s%s\n", fakeArchivePrefix, image)
+	return err
+}
+
+//Load adds image from archive created by Save to Images
+func (f *Fake) Load(r io.Reader, progress io.Writer) error {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if !strings.HasPrefix(line, fakeArchivePrefix) {
+		return errors.New("incorrect image archive")
+	}
+	image := strings.TrimSpace(strings.TrimPrefix(line, fakeArchivePrefix))
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.addImage(image, false)
+	if progress != nil {
+		_, _ = fmt.Fprintf(progress, "loaded %s\n", image)
+	}
+	return nil
+}
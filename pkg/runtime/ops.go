@@ -0,0 +1,29 @@
+package runtime
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	logger zerolog.Logger
+)
+
+func init() {
+	logger = log.With().
+		Str("package", "runtime").
+		Logger()
+}
+
+func debug(format string, v ...interface{}) {
+	logger.
+		Debug().
+		Msgf(format, v...)
+}
+
+func errRegister(err error) {
+	logger.
+		Panic().
+		Err(err).
+		Msg("runtime registration failed")
+}
@@ -0,0 +1,158 @@
+package runtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultRuntime = "docker"
+)
+
+//Runtime is container engine able to pull images and run component jobs
+type Runtime interface {
+	//Pull pulls image (authenticating in registry if auth is not nil) rendering progress to progress writer (if not
+	//nil) and returns pull logs
+	Pull(image string, auth *Auth, progress io.Writer) (string, error)
+	//Run runs job in new container and removes container when it finishes
+	Run(ctx context.Context, job Job) error
+	//Remove removes image
+	Remove(image string) error
+	//Inspect returns details of local image
+	Inspect(image string) (*ImageInfo, error)
+	//Save writes local image as tar archive to w
+	Save(image string, w io.Writer) error
+	//Load loads images from tar archive created by Save rendering progress to progress writer (if not nil)
+	Load(r io.Reader, progress io.Writer) error
+}
+
+//Job holds information required to run single component command in container
+type Job struct {
+	Image                string
+	Command              string
+	Args                 []string
+	WorkDirectory        string
+	Mounts               []string
+	MountPath            string
+	EnvironmentVariables map[string]string
+	Interactive          bool
+	Timeout              time.Duration
+	StopGracePeriod      time.Duration
+	Stdout               io.Writer
+	Stderr               io.Writer
+}
+
+//Auth holds credentials used to pull image from registry
+type Auth struct {
+	ServerAddress string
+	Username      string
+	Password      string
+	IdentityToken string
+}
+
+//ImageInfo holds details of local image
+type ImageInfo struct {
+	ID          string
+	RepoTags    []string
+	RepoDigests []string
+}
+
+//Digest returns content digest (like sha256:...) of image in registry it was pulled from, taken from RepoDigests
+//entry of repository of provided image reference. Empty string is returned if image was not pulled from that
+//repository (i.e. it was built or loaded locally).
+func (i *ImageInfo) Digest(image string) string {
+	repository := ImageRepository(image)
+	for _, rd := range i.RepoDigests {
+		if j := strings.LastIndex(rd, "@"); j >= 0 && ImageRepository(rd[:j]) == repository {
+			return rd[j+1:]
+		}
+	}
+	return ""
+}
+
+//ImageRepository returns repository part of image reference (without tag and digest) in short form used by Docker,
+//i.e. without docker.io registry and library namespace
+func ImageRepository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i >= 0 && !strings.Contains(image[i+1:], "/") {
+		image = image[:i]
+	}
+	for _, prefix := range []string{"docker.io/", "index.docker.io/", "library/"} {
+		image = strings.TrimPrefix(image, prefix)
+	}
+	return image
+}
+
+//ExitError is returned by Runtime.Run when container finished with non-zero exit code
+type ExitError struct {
+	ExitCode  int
+	OOMKilled bool
+	TimedOut  bool
+}
+
+func (e *ExitError) Error() string {
+	if e.TimedOut {
+		return fmt.Sprintf("container was stopped because it exceeded timeout (exit code %d)", e.ExitCode)
+	}
+	if e.OOMKilled {
+		return fmt.Sprintf("container was killed because it ran out of memory (exit code %d)", e.ExitCode)
+	}
+	return fmt.Sprintf("container exited with code %d", e.ExitCode)
+}
+
+//Factory creates Runtime instance
+type Factory func() (Runtime, error)
+
+var (
+	factoriesMutex sync.RWMutex
+	factories      = make(map[string]Factory)
+)
+
+//Register makes Runtime available under provided name. It's expected to be called from init function of package
+//implementing Runtime.
+func Register(name string, factory Factory) {
+	factoriesMutex.Lock()
+	defer factoriesMutex.Unlock()
+	if factory == nil {
+		errRegister(errors.New(fmt.Sprintf("nil factory for runtime %s", name)))
+	}
+	if _, ok := factories[name]; ok {
+		errRegister(errors.New(fmt.Sprintf("runtime %s registered twice", name)))
+	}
+	factories[name] = factory
+}
+
+//New creates Runtime registered under provided name. Empty name means DefaultRuntime.
+func New(name string) (Runtime, error) {
+	if name == "" {
+		name = DefaultRuntime
+	}
+	factoriesMutex.RLock()
+	factory, ok := factories[name]
+	factoriesMutex.RUnlock()
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("unknown runtime %s (available: %s)", name, strings.Join(Names(), ", ")))
+	}
+	debug("will try to create runtime %s", name)
+	return factory()
+}
+
+//Names returns sorted names of all registered runtimes
+func Names() []string {
+	factoriesMutex.RLock()
+	defer factoriesMutex.RUnlock()
+	var names []string
+	for n := range factories {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,164 @@
+package runtime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func setup() {
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+}
+
+func TestNew(t *testing.T) {
+	setup()
+	fake := NewFake()
+	Register("test-fake", func() (Runtime, error) {
+		return fake, nil
+	})
+
+	tests := []struct {
+		name        string
+		runtimeName string
+		want        Runtime
+		wantErr     error
+	}{
+		{
+			name:        "registered",
+			runtimeName: "test-fake",
+			want:        fake,
+		},
+		{
+			name:        "unknown",
+			runtimeName: "unknown",
+			wantErr:     errors.New("unknown runtime unknown \\(available: .*test-fake.*\\)"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.runtimeName)
+			if err != nil || tt.wantErr != nil {
+				if err == nil || tt.wantErr == nil || !regexp.MustCompile(tt.wantErr.Error()).MatchString(err.Error()) {
+					t.Errorf("got error %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFake(t *testing.T) {
+	setup()
+	f := NewFake()
+	if _, err := f.Inspect("i1"); err == nil {
+		t.Errorf("expected error on inspect of not pulled image")
+	}
+	if _, err := f.Pull("i1", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	info, err := f.Inspect("i1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(info.RepoTags, []string{"i1"}) {
+		t.Errorf("got tags %#v", info.RepoTags)
+	}
+	var archive bytes.Buffer
+	if err := f.Save("i1", &archive); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Remove("i1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Load(&archive, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Inspect("i1"); err != nil {
+		t.Errorf("expected image to be loaded: %v", err)
+	}
+	if err := f.Remove("i1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Remove("i1"); err == nil {
+		t.Errorf("expected error on remove of not existing image")
+	}
+
+	f.RunFunc = func(_ context.Context, job Job) error {
+		return &ExitError{ExitCode: 3}
+	}
+	err = f.Run(context.Background(), Job{Command: "c1"})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode != 3 {
+		t.Errorf("got error %v", err)
+	}
+	if len(f.Jobs) != 1 || f.Jobs[0].Command != "c1" {
+		t.Errorf("got jobs %#v", f.Jobs)
+	}
+}
+
+func TestImageInfo_Digest(t *testing.T) {
+	info := &ImageInfo{
+		RepoDigests: []string{
+			"hashicorp/terraform@sha256:aaa",
+			"myregistry.azurecr.io/terraform@sha256:bbb",
+			"localhost:5000/ubuntu@sha256:ccc",
+			"ubuntu@sha256:ddd",
+		},
+	}
+	tests := []struct {
+		name  string
+		image string
+		want  string
+	}{
+		{
+			name:  "short name",
+			image: "hashicorp/terraform:0.12.28",
+			want:  "sha256:aaa",
+		},
+		{
+			name:  "full docker hub name",
+			image: "docker.io/hashicorp/terraform:0.12.28",
+			want:  "sha256:aaa",
+		},
+		{
+			name:  "other registry",
+			image: "myregistry.azurecr.io/terraform:0.12.28",
+			want:  "sha256:bbb",
+		},
+		{
+			name:  "registry with port",
+			image: "localhost:5000/ubuntu",
+			want:  "sha256:ccc",
+		},
+		{
+			name:  "official image",
+			image: "docker.io/library/ubuntu:20.04",
+			want:  "sha256:ddd",
+		},
+		{
+			name:  "reference with digest",
+			image: "ubuntu@sha256:eee",
+			want:  "sha256:ddd",
+		},
+		{
+			name:  "other repository",
+			image: "terraform:0.12.28",
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := info.Digest(tt.image); got != tt.want {
+				t.Errorf("got digest %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
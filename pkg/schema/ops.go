@@ -0,0 +1,22 @@
+package schema
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	logger zerolog.Logger
+)
+
+func init() {
+	logger = log.With().
+		Str("package", "schema").
+		Logger()
+}
+
+func debug(format string, v ...interface{}) {
+	logger.
+		Debug().
+		Msgf(format, v...)
+}
@@ -0,0 +1,118 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+//LegacyVersion is version of documents created before schema versions were introduced (without version field)
+const LegacyVersion = "v0"
+
+//Migration upgrades document from version From to version To. Apply can be nil if only version changes.
+type Migration struct {
+	From  string
+	To    string
+	Apply func(document map[interface{}]interface{}) error
+}
+
+//Schema describes versions of single kind of yaml file
+type Schema struct {
+	Kind       string
+	Current    string
+	Migrations []Migration
+}
+
+//NewerVersionError is returned when document has version newer than Current version of Schema, which means it was
+//written by newer version of e
+type NewerVersionError struct {
+	Kind      string
+	Version   string
+	Supported string
+}
+
+func (e *NewerVersionError) Error() string {
+	return fmt.Sprintf("%s file has schema version %s but this version of e supports only up to %s, please upgrade e", e.Kind, e.Version, e.Supported)
+}
+
+//Upgrade checks kind and version of document and migrates it to Current version. It returns migrated document and
+//version document had before migration (equal to Current if no migration was needed). Empty documents and
+//documents which are not yaml mappings are returned unchanged, so decoding them reports original error.
+func (s *Schema) Upgrade(data []byte) ([]byte, string, error) {
+	document := make(map[interface{}]interface{})
+	if err := yaml.Unmarshal(data, &document); err != nil || len(document) == 0 {
+		return data, s.Current, nil
+	}
+	if kind, ok := document["kind"]; ok && kind != s.Kind {
+		return nil, "", errors.New(fmt.Sprintf("expected %s file but found kind %v", s.Kind, kind))
+	}
+	version := LegacyVersion
+	if v, ok := document["version"]; ok {
+		version = fmt.Sprint(v)
+	}
+	if version == s.Current {
+		return data, version, nil
+	}
+	migrations, err := s.path(version)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, m := range migrations {
+		debug("will try to migrate %s from %s to %s", s.Kind, m.From, m.To)
+		if m.Apply != nil {
+			err = m.Apply(document)
+			if err != nil {
+				return nil, "", errors.New(fmt.Sprintf("migration of %s from %s to %s failed: %v", s.Kind, m.From, m.To, err))
+			}
+		}
+		document["version"] = m.To
+	}
+	document["kind"] = s.Kind
+	migrated, err := yaml.Marshal(document)
+	if err != nil {
+		return nil, "", err
+	}
+	return migrated, version, nil
+}
+
+//path returns migrations leading from version to Current version
+func (s *Schema) path(version string) ([]Migration, error) {
+	var migrations []Migration
+	for v := version; v != s.Current; {
+		found := false
+		for _, m := range s.Migrations {
+			if m.From == v {
+				migrations = append(migrations, m)
+				v = m.To
+				found = true
+				break
+			}
+		}
+		if !found {
+			if isNewer(version, s.Current) {
+				return nil, &NewerVersionError{Kind: s.Kind, Version: version, Supported: s.Current}
+			}
+			return nil, errors.New(fmt.Sprintf("unknown %s schema version %s", s.Kind, version))
+		}
+		if len(migrations) > len(s.Migrations) {
+			return nil, errors.New(fmt.Sprintf("migrations of %s form a cycle", s.Kind))
+		}
+	}
+	return migrations, nil
+}
+
+//isNewer checks if version is newer than other. Versions are expected in vN form.
+func isNewer(version string, other string) bool {
+	v, err := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	if err != nil {
+		return false
+	}
+	o, err := strconv.Atoi(strings.TrimPrefix(other, "v"))
+	if err != nil {
+		return false
+	}
+	return v > o
+}
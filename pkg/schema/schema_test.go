@@ -0,0 +1,104 @@
+package schema
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestSchema_Upgrade(t *testing.T) {
+	s := &Schema{
+		Kind:    "Thing",
+		Current: "v2",
+		Migrations: []Migration{
+			{From: LegacyVersion, To: "v1"},
+			{From: "v1", To: "v2", Apply: func(document map[interface{}]interface{}) error {
+				document["renamed"] = document["old"]
+				delete(document, "old")
+				return nil
+			}},
+		},
+	}
+	tests := []struct {
+		name        string
+		data        string
+		want        map[interface{}]interface{}
+		wantVersion string
+		wantErr     error
+	}{
+		{
+			name:        "current",
+			data:        "version: v2\nkind: Thing\nrenamed: x\n",
+			want:        map[interface{}]interface{}{"version": "v2", "kind": "Thing", "renamed": "x"},
+			wantVersion: "v2",
+		},
+		{
+			name:        "legacy",
+			data:        "old: x\n",
+			want:        map[interface{}]interface{}{"version": "v2", "kind": "Thing", "renamed": "x"},
+			wantVersion: LegacyVersion,
+		},
+		{
+			name:        "v1",
+			data:        "version: v1\nkind: Thing\nold: x\n",
+			want:        map[interface{}]interface{}{"version": "v2", "kind": "Thing", "renamed": "x"},
+			wantVersion: "v1",
+		},
+		{
+			name:    "newer",
+			data:    "version: v3\nkind: Thing\n",
+			wantErr: errors.New("Thing file has schema version v3 but this version of e supports only up to v2, please upgrade e"),
+		},
+		{
+			name:    "unknown",
+			data:    "version: beta\nkind: Thing\n",
+			wantErr: errors.New("unknown Thing schema version beta"),
+		},
+		{
+			name:    "other kind",
+			data:    "version: v2\nkind: Other\n",
+			wantErr: errors.New("expected Thing file but found kind Other"),
+		},
+		{
+			name:        "not mapping",
+			data:        "incorrect file",
+			wantVersion: "v2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, version, err := s.Upgrade([]byte(tt.data))
+			if err != nil || tt.wantErr != nil {
+				if err == nil || tt.wantErr == nil || err.Error() != tt.wantErr.Error() {
+					t.Errorf("got error %v, want error %v", err, tt.wantErr)
+				}
+				return
+			}
+			if version != tt.wantVersion {
+				t.Errorf("got version %s, want %s", version, tt.wantVersion)
+			}
+			if tt.want == nil {
+				if string(got) != tt.data {
+					t.Errorf("expected document to be unchanged but got %s", got)
+				}
+				return
+			}
+			document := make(map[interface{}]interface{})
+			err = yaml.Unmarshal(got, &document)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(document, tt.want) {
+				t.Errorf("got = %v, want %v", document, tt.want)
+			}
+		})
+	}
+
+	var newerErr *NewerVersionError
+	_, _, err := s.Upgrade([]byte("version: v10\n"))
+	if !errors.As(err, &newerErr) || newerErr.Version != "v10" {
+		t.Errorf("got error %v, want NewerVersionError", err)
+	}
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+//Filesystem is Backend storing files in local directory
+type Filesystem struct {
+	root string
+}
+
+//NewFilesystem creates Filesystem backend storing files in root directory
+func NewFilesystem(root string) *Filesystem {
+	return &Filesystem{root: root}
+}
+
+func (f *Filesystem) Read(key string) ([]byte, error) {
+	p := path.Join(f.root, key)
+	debug("will try to read file %s", p)
+	if _, err := os.Stat(p); err != nil {
+		return nil, err
+	}
+	return ioutil.ReadFile(p)
+}
+
+func (f *Filesystem) Write(key string, data []byte) error {
+	p := path.Join(f.root, key)
+	debug("will try to write file %s", p)
+	err := os.MkdirAll(path.Dir(p), 0755)
+	if err != nil {
+		return err
+	}
+	//data is written to temporary file in the same directory and renamed so that file is replaced atomically
+	file, err := ioutil.TempFile(path.Dir(p), "."+path.Base(p)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(data)
+	if err == nil {
+		err = file.Sync()
+	}
+	if err == nil {
+		err = file.Chmod(0644)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), p)
+}
+
+func (f *Filesystem) Create(key string, data []byte) error {
+	p := path.Join(f.root, key)
+	debug("will try to create file %s", p)
+	err := os.MkdirAll(path.Dir(p), 0755)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(p, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(p)
+		return err
+	}
+	return nil
+}
+
+func (f *Filesystem) Delete(key string) error {
+	p := path.Join(f.root, key)
+	debug("will try to remove file %s", p)
+	err := os.Remove(p)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (f *Filesystem) List(prefix string) ([]string, error) {
+	p := path.Join(f.root, prefix)
+	debug("will try to list directory %s", p)
+	items, err := ioutil.ReadDir(p)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, i := range items {
+		if strings.HasPrefix(i.Name(), ".") {
+			continue
+		}
+		if i.IsDir() {
+			names = append(names, i.Name()+"/")
+		} else {
+			names = append(names, i.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
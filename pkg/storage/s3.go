@@ -0,0 +1,343 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	defaultS3Region = "us-east-1"
+	s3TimeFormat    = "20060102T150405Z"
+	s3DateFormat    = "20060102"
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+)
+
+//S3Config holds configuration of S3 compatible object store (AWS S3, MinIO, etc.). Credentials not provided here
+//are taken from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables.
+type S3Config struct {
+	Endpoint     string `yaml:"endpoint"`
+	Region       string `yaml:"region,omitempty"`
+	Bucket       string `yaml:"bucket"`
+	Prefix       string `yaml:"prefix,omitempty"`
+	AccessKey    string `yaml:"access-key,omitempty"`
+	SecretKey    string `yaml:"secret-key,omitempty"`
+	SessionToken string `yaml:"session-token,omitempty"`
+}
+
+//S3 is Backend storing files as objects in bucket of S3 compatible object store. Requests use path-style addressing
+//and are signed with AWS Signature Version 4.
+type S3 struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+//s3Credentials are used to sign requests
+type s3Credentials struct {
+	accessKey    string
+	secretKey    string
+	sessionToken string
+}
+
+//NewS3 creates S3 backend
+func NewS3(config S3Config) (*S3, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("empty s3 endpoint")
+	}
+	if config.Bucket == "" {
+		return nil, errors.New("empty s3 bucket")
+	}
+	if !strings.Contains(config.Endpoint, "://") {
+		config.Endpoint = "https://" + config.Endpoint
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if config.Region == "" {
+		config.Region = defaultS3Region
+	}
+	if config.Prefix != "" && !strings.HasSuffix(config.Prefix, "/") {
+		config.Prefix += "/"
+	}
+	if config.AccessKey == "" {
+		config.AccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+	if config.SecretKey == "" {
+		config.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if config.SessionToken == "" {
+		config.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	return &S3{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 30 * time.Second},
+		now:      time.Now,
+	}, nil
+}
+
+func (s *S3) Read(key string) ([]byte, error) {
+	response, body, err := s.do(http.MethodGet, s.config.Prefix+key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode == http.StatusNotFound {
+		return nil, &os.PathError{Op: "read", Path: s.objectName(key), Err: os.ErrNotExist}
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, s3Error(http.MethodGet, s.objectName(key), response, body)
+	}
+	return body, nil
+}
+
+func (s *S3) Write(key string, data []byte) error {
+	response, body, err := s.do(http.MethodPut, s.config.Prefix+key, nil, nil, data)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return s3Error(http.MethodPut, s.objectName(key), response, body)
+	}
+	return nil
+}
+
+//Create uses conditional write (If-None-Match: *) so object store rejects request if object already exists
+func (s *S3) Create(key string, data []byte) error {
+	header := http.Header{}
+	header.Set("If-None-Match", "*")
+	response, body, err := s.do(http.MethodPut, s.config.Prefix+key, nil, header, data)
+	if err != nil {
+		return err
+	}
+	switch response.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		return &os.PathError{Op: "create", Path: s.objectName(key), Err: os.ErrExist}
+	}
+	return s3Error(http.MethodPut, s.objectName(key), response, body)
+}
+
+func (s *S3) Delete(key string) error {
+	response, body, err := s.do(http.MethodDelete, s.config.Prefix+key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+	return s3Error(http.MethodDelete, s.objectName(key), response, body)
+}
+
+//listBucketResult is response of ListObjectsV2 request
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	CommonPrefixes []struct {
+		Prefix string `xml:"Prefix"`
+	} `xml:"CommonPrefixes"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+func (s *S3) List(prefix string) ([]string, error) {
+	fullPrefix := s.config.Prefix + prefix
+	var names []string
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("delimiter", "/")
+		query.Set("prefix", fullPrefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		response, body, err := s.do(http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		if response.StatusCode != http.StatusOK {
+			return nil, s3Error(http.MethodGet, s.objectName(prefix), response, body)
+		}
+		result := &listBucketResult{}
+		err = xml.Unmarshal(body, result)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range result.CommonPrefixes {
+			names = append(names, strings.TrimPrefix(p.Prefix, fullPrefix))
+		}
+		for _, c := range result.Contents {
+			if c.Key != fullPrefix {
+				names = append(names, strings.TrimPrefix(c.Key, fullPrefix))
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *S3) objectName(key string) string {
+	return fmt.Sprintf("s3://%s/%s%s", s.config.Bucket, s.config.Prefix, key)
+}
+
+//do sends signed request for object (or bucket if object is empty) and returns response with read body
+func (s *S3) do(method string, object string, query url.Values, header http.Header, data []byte) (*http.Response, []byte, error) {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.config.Bucket
+	if object != "" {
+		u.Path += "/" + object
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	u.RawQuery = canonicalQuery(query)
+	debug("will try to send %s %s", method, u.String())
+	request, err := http.NewRequest(method, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	request.ContentLength = int64(len(data))
+	for k, v := range header {
+		request.Header[k] = v
+	}
+	sign(request, hashHex(data), s3Credentials{
+		accessKey:    s.config.AccessKey,
+		secretKey:    s.config.SecretKey,
+		sessionToken: s.config.SessionToken,
+	}, s.config.Region, s.now())
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return response, body, nil
+}
+
+//s3Error creates error from S3 error response
+func s3Error(method string, object string, response *http.Response, body []byte) error {
+	e := &struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}{}
+	if err := xml.Unmarshal(body, e); err != nil || e.Code == "" {
+		return errors.New(fmt.Sprintf("s3 %s %s failed: %s", method, object, response.Status))
+	}
+	return errors.New(fmt.Sprintf("s3 %s %s failed: %s: %s: %s", method, object, response.Status, e.Code, e.Message))
+}
+
+//sign adds AWS Signature Version 4 authorization to request. All request headers (and host) are signed.
+func sign(request *http.Request, payloadHash string, credentials s3Credentials, region string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format(s3TimeFormat)
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if credentials.sessionToken != "" {
+		request.Header.Set("X-Amz-Security-Token", credentials.sessionToken)
+	}
+
+	headers := map[string]string{"host": request.URL.Host}
+	for k, v := range request.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	var names []string
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		request.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := strings.Join([]string{t.Format(s3DateFormat), region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+credentials.secretKey), t.Format(s3DateFormat))
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s", s3Algorithm, credentials.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hashHex(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+//canonicalQuery encodes query sorted by keys as required by Signature Version 4
+func canonicalQuery(query url.Values) string {
+	var keys []string
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+//uriEncode encodes every byte except unreserved characters (and slash if encodeSlash is not set)
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			b.WriteString(fmt.Sprintf("%%%02X", c))
+		}
+	}
+	return b.String()
+}
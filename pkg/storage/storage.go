@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	TypeFilesystem = "filesystem"
+	TypeS3         = "s3"
+)
+
+//Backend stores environment state files under slash separated keys (like <uuid>/config.yaml)
+type Backend interface {
+	//Read returns content stored under key. If there is no such key returned error satisfies os.IsNotExist.
+	Read(key string) ([]byte, error)
+	//Write stores data under key. Readers see either previous or new content, never partially written one.
+	Write(key string, data []byte) error
+	//Create stores data under key only if there is no such key yet. Otherwise returned error satisfies os.IsExist.
+	Create(key string, data []byte) error
+	//Delete removes key. It's not an error if key doesn't exist.
+	Delete(key string) error
+	//List returns names of entries directly under prefix (which is empty or ends with /). Names of entries
+	//containing other entries end with /.
+	List(prefix string) ([]string, error)
+}
+
+//Config selects and configures Backend
+type Config struct {
+	Type string    `yaml:"type"`
+	S3   *S3Config `yaml:"s3,omitempty"`
+}
+
+//New creates Backend according to config. Filesystem backend (used also when config is nil) stores files in root
+//directory.
+func New(config *Config, root string) (Backend, error) {
+	if config == nil || config.Type == "" || config.Type == TypeFilesystem {
+		return NewFilesystem(root), nil
+	}
+	if config.Type == TypeS3 {
+		if config.S3 == nil {
+			return nil, errors.New("missing s3 configuration of storage")
+		}
+		return NewS3(*config.S3)
+	}
+	return nil, errors.New(fmt.Sprintf("unknown storage type %s (available: %s, %s)", config.Type, TypeFilesystem, TypeS3))
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *Config
+		want    string
+		wantErr string
+	}{
+		{name: "nil", config: nil, want: "*storage.Filesystem"},
+		{name: "filesystem", config: &Config{Type: TypeFilesystem}, want: "*storage.Filesystem"},
+		{name: "s3", config: &Config{Type: TypeS3, S3: &S3Config{Endpoint: "localhost:9000", Bucket: "b"}}, want: "*storage.S3"},
+		{name: "s3 without config", config: &Config{Type: TypeS3}, wantErr: "missing s3 configuration of storage"},
+		{name: "unknown", config: &Config{Type: "ftp"}, wantErr: "unknown storage type ftp (available: filesystem, s3)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.config, "/tmp")
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("got error %v, want %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reflect.TypeOf(got).String() != tt.want {
+				t.Errorf("got = %T, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilesystem(t *testing.T) {
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	dir, err := ioutil.TempDir("", "*-e-storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	f := NewFilesystem(dir)
+
+	_, err = f.Read("e1/config.yaml")
+	if !os.IsNotExist(err) {
+		t.Errorf("got error %v, want not exist error", err)
+	}
+	for _, k := range []string{"e1/config.yaml", "e2/config.yaml", "top.yaml"} {
+		err = f.Write(k, []byte(k))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := f.Read("e1/config.yaml")
+	if err != nil || string(data) != "e1/config.yaml" {
+		t.Errorf("got content %s, error %v", data, err)
+	}
+	names, err := f.List("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"e1/", "e2/", "top.yaml"}) {
+		t.Errorf("got names %#v", names)
+	}
+	err = f.Write("e1/config.yaml", []byte("overwritten"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names, err = f.List("e1/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(names, []string{"config.yaml"}) {
+		t.Errorf("expected no temporary files left, got names %#v", names)
+	}
+
+	err = f.Create("e1/lock.yaml", []byte("first"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.Create("e1/lock.yaml", []byte("second"))
+	if !os.IsExist(err) {
+		t.Errorf("got error %v, want exist error", err)
+	}
+	data, err = f.Read("e1/lock.yaml")
+	if err != nil || string(data) != "first" {
+		t.Errorf("got content %s, error %v", data, err)
+	}
+
+	err = f.Delete("e1/config.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.Delete("e1/config.yaml")
+	if err != nil {
+		t.Errorf("expected delete of missing key to succeed but got %v", err)
+	}
+}
@@ -1,7 +1,14 @@
 package util
 
 import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"path"
+	"strings"
 )
 
 const (
@@ -9,8 +16,13 @@ const (
 	DefaultConfigFileName              string = "config.yaml"
 	DefaultEnvironmentsSubdirectory    string = "environments"
 	DefaultEnvironmentConfigFileName   string = "config.yaml"
+	DefaultEnvironmentLockFileName     string = "lock.yaml"
 	DefaultComponentRunsSubdirectory   string = "runs"
 	DefaultComponentMountsSubdirectory string = "mounts"
+	DefaultComponentPullsSubdirectory  string = "pulls"
+	DefaultRegistriesFileName          string = "registries.yaml"
+	DefaultImportedRepositoryFileName  string = "imported.yaml"
+	DefaultRepositoriesSubdirectory    string = "repositories"
 
 	GithubUrl                   = "https://raw.githubusercontent.com"
 	DefaultRepository           = "mkyc/epiphany-wrapper-poc-repo"
@@ -23,6 +35,7 @@ var (
 	UsedConfigurationDirectory string
 	UsedEnvironmentDirectory   string
 	UsedRepositoryFile         string
+	UsedRuntime                string
 )
 
 func EnsureDirectory(directory string) {
@@ -43,3 +56,94 @@ func GetHomeDirectory() string {
 	debug("got user home directory: %s", home)
 	return home
 }
+
+//CopyDirectory recursively copies content of src directory into dst directory preserving file modes and symlinks
+func CopyDirectory(src string, dst string) error {
+	debug("will try to copy directory %s to %s", src, dst)
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(dst, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	items, err := ioutil.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, i := range items {
+		s := path.Join(src, i.Name())
+		d := path.Join(dst, i.Name())
+		switch {
+		case i.IsDir():
+			err = CopyDirectory(s, d)
+		case i.Mode()&os.ModeSymlink != 0:
+			var target string
+			target, err = os.Readlink(s)
+			if err == nil {
+				err = os.Symlink(target, d)
+			}
+		default:
+			err = copyFile(s, d, i.Mode().Perm())
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src string, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+//ParseEnvironmentVariable splits KEY=VALUE string into key and value
+func ParseEnvironmentVariable(kv string) (string, string, error) {
+	i := strings.Index(kv, "=")
+	if i < 1 {
+		return "", "", errors.New(fmt.Sprintf("incorrect environment variable %s, expected KEY=VALUE", kv))
+	}
+	return kv[:i], kv[i+1:], nil
+}
+
+//ReadEnvironmentFile reads file with KEY=VALUE line per variable. Empty lines and lines starting with # are skipped.
+func ReadEnvironmentFile(filePath string) (map[string]string, error) {
+	debug("will try to read environment variables from file %s", filePath)
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	envs := make(map[string]string)
+	s := bufio.NewScanner(file)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, v, err := ParseEnvironmentVariable(line)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("%s:%d: %s", filePath, n, err.Error()))
+		}
+		envs[k] = v
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return envs, nil
+}
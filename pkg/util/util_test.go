@@ -1,9 +1,11 @@
 package util
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"path"
+	"reflect"
 	"testing"
 
 	"github.com/rs/zerolog"
@@ -43,3 +45,107 @@ func TestEnsureDirectory(t *testing.T) {
 		})
 	}
 }
+
+func TestCopyDirectory(t *testing.T) {
+	setup()
+	parentDir := os.TempDir()
+	mainDirectory, err := ioutil.TempDir(parentDir, "*-e-util-copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mainDirectory)
+
+	src := path.Join(mainDirectory, "src")
+	EnsureDirectory(path.Join(src, "l1", "l2"))
+	files := map[string][]byte{
+		"f1":       []byte("content 1"),
+		"l1/f2":    []byte("content 2"),
+		"l1/l2/f3": []byte("content 3"),
+	}
+	for f, c := range files {
+		err = ioutil.WriteFile(path.Join(src, f), c, 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = os.Symlink("f2", path.Join(src, "l1", "link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := path.Join(mainDirectory, "dst")
+	err = CopyDirectory(src, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for f, c := range files {
+		got, err := ioutil.ReadFile(path.Join(dst, f))
+		if err != nil {
+			t.Errorf("expected file %s not found: %v", f, err)
+			continue
+		}
+		if !bytes.Equal(got, c) {
+			t.Errorf("file %s content is %s, want %s", f, got, c)
+		}
+		info, _ := os.Stat(path.Join(dst, f))
+		if info.Mode().Perm() != 0600 {
+			t.Errorf("file %s mode is %v, want %v", f, info.Mode().Perm(), os.FileMode(0600))
+		}
+	}
+	target, err := os.Readlink(path.Join(dst, "l1", "link"))
+	if err != nil || target != "f2" {
+		t.Errorf("expected symlink to f2 but got %s (%v)", target, err)
+	}
+}
+
+func TestReadEnvironmentFile(t *testing.T) {
+	setup()
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "correct",
+			content: "# comment\nTF_LOG=DEBUG\n\nA=b=c\nEMPTY=\n",
+			want: map[string]string{
+				"TF_LOG": "DEBUG",
+				"A":      "b=c",
+				"EMPTY":  "",
+			},
+		},
+		{
+			name:    "missing equals sign",
+			content: "TF_LOG\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing key",
+			content: "=value\n",
+			wantErr: true,
+		},
+	}
+	parentDir := os.TempDir()
+	mainDirectory, err := ioutil.TempDir(parentDir, "*-e-util-env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(mainDirectory)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envFile := path.Join(mainDirectory, "env")
+			err := ioutil.WriteFile(envFile, []byte(tt.content), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadEnvironmentFile(envFile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %t", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}